@@ -4,7 +4,6 @@ go 1.16
 
 require (
 	github.com/aws/aws-sdk-go v1.31.9 // indirect
-	github.com/cenkalti/backoff/v4 v4.1.0
 	github.com/google/fhir/go v0.0.0-20201203001644-a2580b6ea022
 	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
 	github.com/hashicorp/go-getter v1.5.1 // indirect
@@ -18,8 +17,10 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.6.1
 	github.com/zclconf/go-cty v1.7.1 // indirect
-	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad // indirect
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
+	golang.org/x/net v0.0.0-20210119194325-5f4716e94777
 	golang.org/x/sys v0.0.0-20210123231150-1d476976d117 // indirect
 	golang.org/x/tools v0.1.0 // indirect
 	google.golang.org/api v0.34.0 // indirect
+	gopkg.in/yaml.v2 v2.3.0
 )
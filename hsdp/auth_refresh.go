@@ -0,0 +1,208 @@
+package hsdp
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+// providerSession is the key of the provider login in iamSessions. Principals
+// are registered under Principal.key, which never collides with it
+const providerSession = "provider"
+
+// tokenRenewMargin is how many seconds before expiry a token is renewed. The
+// IAM client stops handing out a token it cannot refresh 60 seconds before it
+// expires, so sessions are renewed well before that
+const tokenRenewMargin = 300
+
+// iamSessions keeps track of the IAM logins made by the provider so tokens
+// which expire or get revoked during a long apply can be renewed in place
+type iamSessions struct {
+	sync.Mutex
+	sessions map[string]*iamSession
+}
+
+// iamSession is a logged in IAM client together with the means to log in again
+type iamSession struct {
+	sync.Mutex
+	client *iam.Client
+	login  func(client *iam.Client) error
+}
+
+// register adds or replaces the session known under key
+func (s *iamSessions) register(key string, client *iam.Client, login func(client *iam.Client) error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*iamSession)
+	}
+	s.sessions[key] = &iamSession{client: client, login: login}
+}
+
+// all returns the registered sessions
+func (s *iamSessions) all() []*iamSession {
+	s.Lock()
+	defer s.Unlock()
+	sessions := make([]*iamSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// lookup returns the session which issued token, if any
+func (s *iamSessions) lookup(token string) *iamSession {
+	for _, session := range s.all() {
+		if session.client.Token() == token {
+			return session
+		}
+	}
+	return nil
+}
+
+// renewExpiring renews the session of client when its token is about to
+// expire. It is called before a request is built: the IAM client refreshes
+// tokens by itself only with a refresh token, which service identities do not
+// get, so without it their requests would go out without a token
+func (s *iamSessions) renewExpiring(client *iam.Client) error {
+	for _, session := range s.all() {
+		if session.client == client {
+			return session.renewExpiring()
+		}
+	}
+	return nil
+}
+
+// expiring reports whether the token of the session is about to expire
+func (s *iamSession) expiring() bool {
+	return s.client.Expires()-time.Now().Unix() < tokenRenewMargin
+}
+
+// renewExpiring logs in again when the token of the session is about to
+// expire. A failed login is only reported once the token can no longer be used
+func (s *iamSession) renewExpiring() error {
+	if !s.expiring() {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if !s.expiring() {
+		return nil
+	}
+	err := s.login(s.client)
+	if s.client.Token() == "" {
+		if err == nil {
+			err = ErrTokenRenewFailed
+		}
+		return err
+	}
+	return nil
+}
+
+// renew gets a fresh token for a session after token was rejected. When
+// another request already renewed the session in the mean time its token is
+// returned
+func (s *iamSession) renew(token string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if current := s.client.Token(); current != "" && current != token {
+		return current, nil
+	}
+	if err := s.login(s.client); err != nil {
+		return "", err
+	}
+	current := s.client.Token()
+	if current == "" {
+		return "", ErrTokenRenewFailed
+	}
+	return current, nil
+}
+
+// authTransport renews IAM tokens which are about to expire before a request
+// is sent and replays requests once which IAM or a downstream service rejected
+// with a 401 after renewing the token of the session that made them. Tokens
+// which already expired are renewed before the request is built, see
+// iamSessions.renewExpiring
+type authTransport struct {
+	next     http.RoundTripper
+	sessions *iamSessions
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := bearerToken(req)
+	// Logins carry the old token of the client. They are made by renew, which
+	// holds the lock of the session, and must not renew it again
+	if token == "" || tokenRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+	session := t.sessions.lookup(token)
+	if session == nil {
+		return t.next.RoundTrip(req)
+	}
+	if session.expiring() && session.renewExpiring() == nil {
+		if renewed := session.client.Token(); renewed != "" && renewed != token {
+			req = withBearerToken(req, renewed)
+			token = renewed
+		}
+	}
+
+	// Keep the body around so the request can be replayed
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	renewed, renewErr := session.renew(token)
+	if renewErr != nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	retry := withBearerToken(req, renewed)
+	if body != nil {
+		retry.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return t.next.RoundTrip(retry)
+}
+
+// tokenRequest reports whether req goes to the IAM token endpoint. The IAM
+// client puts the path of its requests in the opaque part of the URL
+func tokenRequest(req *http.Request) bool {
+	path := req.URL.Path
+	if req.URL.Opaque != "" {
+		path = req.URL.Opaque
+	}
+	return strings.HasSuffix(path, "/authorize/oauth2/token")
+}
+
+func bearerToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(auth, "Bearer ")
+}
+
+func withBearerToken(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
@@ -0,0 +1,105 @@
+package hsdp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/philips-software/go-hsdp-api/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+// testServiceConfig returns a Config which logs in to f as a service identity
+func testServiceConfig(t *testing.T, f *fakeHSDP) *Config {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return testConfigure(t, map[string]interface{}{
+		"credentials_file":    filepath.Join(t.TempDir(), "credentials"),
+		"iam_url":             f.URL,
+		"idm_url":             f.URL,
+		"service_id":          "svc@org.app.prop.philips-healthsuite.com",
+		"service_private_key": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"retry_max":           0,
+	})
+}
+
+// testExpireSoon makes the token of client expire within the minute, as if
+// the apply had been running for most of its lifetime
+func testExpireSoon(client *iam.Client) {
+	client.SetTokens(client.Token(), client.RefreshToken(), client.IDToken(), time.Now().Add(30*time.Second).Unix())
+}
+
+func TestServiceTokenRenewal(t *testing.T) {
+	f := newFakeHSDP(t)
+	groupID := f.put("Group", fakeObject{"name": "group", "managingOrganization": "org-1"})
+	config := testServiceConfig(t, f)
+
+	client, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", client.RefreshToken())
+	assert.Equal(t, 1, f.tokens)
+
+	// The IAM client cannot refresh the token of a service itself
+	testExpireSoon(client)
+	assert.Equal(t, "", client.Token())
+
+	// Requests of the provider renew it
+	var group fakeObject
+	_, err = config.iamRequest(context.Background(), client, http.MethodGet, "/authorize/identity/Group/"+groupID, nil, &group)
+	assert.Nil(t, err)
+	assert.Equal(t, "group", group["name"])
+	assert.Equal(t, 2, f.tokens)
+
+	// So do resources getting their client for a go-hsdp-api call
+	testExpireSoon(client)
+	renewed, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Same(t, client, renewed)
+	_, _, err = renewed.Groups.GetGroupByID(groupID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, f.tokens)
+
+	// A token which is not about to expire is left alone
+	_, err = config.IAMClient()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, f.tokens)
+}
+
+func TestServiceTokenRenewalRejected(t *testing.T) {
+	f := newFakeHSDP(t)
+	groupID := f.put("Group", fakeObject{"name": "group", "managingOrganization": "org-1"})
+	config := testServiceConfig(t, f)
+
+	client, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The token gets revoked and logging in again is rejected. The login
+	// carries the revoked token, it must not try to renew the session again
+	f.failNext(http.MethodGet, "/authorize/identity/Group/", http.StatusUnauthorized, 1)
+	f.failNext(http.MethodPost, "/authorize/oauth2/token", http.StatusUnauthorized, 1)
+	done := make(chan error, 1)
+	go func() {
+		_, err := config.iamRequest(context.Background(), client, http.MethodGet, "/authorize/identity/Group/"+groupID, nil, nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NotNil(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("renewing a rejected token does not return")
+	}
+}
@@ -3,7 +3,6 @@ package hsdp
 import (
 	"fmt"
 	"github.com/google/fhir/go/jsonformat"
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/philips-software/go-hsdp-api/cartel"
 	"github.com/philips-software/go-hsdp-api/cdr"
 	"github.com/philips-software/go-hsdp-api/config"
@@ -14,26 +13,39 @@ import (
 	"github.com/philips-software/go-hsdp-api/s3creds"
 	"github.com/philips-software/go-hsdp-api/stl"
 	"net/http"
-	"os"
+	"sync"
+	"time"
 )
 
 // Config contains configuration for the client
 type Config struct {
 	iam.Config
-	BuildVersion      string
-	ServiceID         string
-	ServicePrivateKey string
-	S3CredsURL        string
-	STLURL            string
-	CartelHost        string
-	CartelToken       string
-	CartelSecret      string
-	CartelNoTLS       bool
-	CartelSkipVerify  bool
-	RetryMax          int
-	UAAUsername       string
-	UAAPassword       string
-	UAAURL            string
+	BuildVersion       string
+	ServiceID          string
+	ServicePrivateKey  string
+	S3CredsURL         string
+	STLURL             string
+	ConsoleURL         string
+	PKIURL             string
+	CartelHost         string
+	CartelToken        string
+	CartelSecret       string
+	CartelNoTLS        bool
+	CartelSkipVerify   bool
+	CABundle           string
+	ClientCert         string
+	ClientKey          string
+	HTTPProxy          string
+	NoProxy            string
+	InsecureSkipVerify bool
+	RetryMax           int
+	RetryWaitMin       time.Duration
+	RetryWaitMax       time.Duration
+	RetryStatusCodes   []int
+	RateLimits         map[string]rateLimit
+	UAAUsername        string
+	UAAPassword        string
+	UAAURL             string
 
 	iamClient        *iam.Client
 	cartelClient     *cartel.Client
@@ -41,7 +53,7 @@ type Config struct {
 	consoleClient    *console.Client
 	pkiClient        *pki.Client
 	stlClient        *stl.Client
-	debugFile        *os.File
+	debugLogger      *debugLogger
 	credsClientErr   error
 	cartelClientErr  error
 	iamClientErr     error
@@ -50,109 +62,220 @@ type Config struct {
 	stlClientErr     error
 	TimeZone         string
 
+	// Clients are set up on first use so a workspace only logs in to
+	// the services it actually manages. Each Once guards its client and error
+	iamOnce     sync.Once
+	cartelOnce  sync.Once
+	credsOnce   sync.Once
+	consoleOnce sync.Once
+	pkiOnce     sync.Once
+	stlOnce     sync.Once
+
+	s3CredsURLOnce     sync.Once
+	resolvedS3CredsURL string
+	stlURLOnce         sync.Once
+	resolvedSTLURL     string
+
+	baseTransport    *http.Transport
+	httpClientOnce   sync.Once
+	sharedHTTPClient *http.Client
+	iamSessions      iamSessions
+	rateLimitersOnce sync.Once
+	limiters         map[string]*serviceLimiter
+	principalClients principalClients
+	permissionsOnce  sync.Once
+	permissions      []string
+	permissionsErr   error
+
 	ma *jsonformat.Marshaller
 }
 
-func (c *Config) IAMClient() (*iam.Client, error) {
-	return c.iamClient, c.iamClientErr
+// IAMClient returns the provider IAM client, or the client of principal when
+// a resource overrides the provider identity with a credentials block. A token
+// which is about to expire is renewed first
+func (c *Config) IAMClient(principal ...*Principal) (*iam.Client, error) {
+	var client *iam.Client
+	var err error
+	if len(principal) > 0 && !principal[0].empty() {
+		client, err = c.principalIAMClient(principal[0])
+	} else {
+		c.iamOnce.Do(c.setupIAMClient)
+		client, err = c.iamClient, c.iamClientErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.iamSessions.renewExpiring(client); err != nil {
+		return nil, err
+	}
+	return client, nil
 }
 
 func (c *Config) CartelClient() (*cartel.Client, error) {
+	c.cartelOnce.Do(c.setupCartelClient)
 	return c.cartelClient, c.cartelClientErr
 }
 
 func (c *Config) S3CredsClient() (*s3creds.Client, error) {
-	return c.s3credsClient, c.credsClientErr
+	c.credsOnce.Do(c.setupS3CredsClient)
+	if c.credsClientErr != nil {
+		return nil, c.credsClientErr
+	}
+	// Renews the token of the IAM client it sends with
+	if _, err := c.IAMClient(); err != nil {
+		return nil, err
+	}
+	return c.s3credsClient, nil
 }
 
 func (c *Config) ConsoleClient() (*console.Client, error) {
+	c.consoleOnce.Do(c.setupConsoleClient)
 	return c.consoleClient, c.consoleClientErr
 }
 
 func (c *Config) STLClient(endpoint ...string) (*stl.Client, error) {
+	c.stlOnce.Do(c.setupSTLClient)
 	return c.stlClient, c.stlClientErr
 }
 
 func (c *Config) PKIClient(regionEnvironment ...string) (*pki.Client, error) {
-	if len(regionEnvironment) == 2 && c.consoleClient != nil && c.iamClient != nil {
+	if len(regionEnvironment) == 2 {
+		iamClient, err := c.IAMClient()
+		if err != nil {
+			return nil, err
+		}
+		consoleClient, err := c.ConsoleClient()
+		if err != nil {
+			return nil, err
+		}
 		region := regionEnvironment[0]
 		environment := regionEnvironment[1]
-		return pki.NewClient(c.consoleClient, c.iamClient, &pki.Config{
+		return pki.NewClient(consoleClient, iamClient, &pki.Config{
 			Region:      region,
 			Environment: environment,
-			DebugLog:    c.DebugLog,
 		})
 	}
-	return c.pkiClient, c.pkiClientErr
+	c.pkiOnce.Do(c.setupPKIClient)
+	if c.pkiClientErr != nil {
+		return nil, c.pkiClientErr
+	}
+	// Renews the token of the IAM client it sends with
+	if _, err := c.IAMClient(); err != nil {
+		return nil, err
+	}
+	return c.pkiClient, nil
 }
 
-func (c *Config) CredentialsClientWithLogin(username, password string) (*s3creds.Client, error) {
-	if c.iamClientErr != nil {
-		return nil, c.iamClientErr
+// pkiClientFor returns a PKI client acting as principal, or the provider PKI client
+func (c *Config) pkiClientFor(principal *Principal) (*pki.Client, error) {
+	if principal.empty() {
+		return c.PKIClient()
+	}
+	iamClient, err := c.IAMClient(principal)
+	if err != nil {
+		return nil, err
+	}
+	consoleClient, err := c.ConsoleClient()
+	if err != nil {
+		return nil, err
 	}
-	newIAMClient, err := c.iamClient.WithLogin(username, password)
+	return pki.NewClient(consoleClient, iamClient, &pki.Config{
+		Region:      c.Region,
+		Environment: c.Environment,
+		PKIURL:      c.PKIURL,
+	})
+}
+
+// CredentialsClientWithLogin returns an S3 Credentials client acting as the
+// given user. The login is cached like the one of a credentials block
+func (c *Config) CredentialsClientWithLogin(username, password string) (*s3creds.Client, error) {
+	iamClient, err := c.IAMClient(&Principal{Username: username, Password: password})
 	if err != nil {
 		return nil, err
 	}
-	return s3creds.NewClient(newIAMClient, &s3creds.Config{
-		BaseURL:  c.S3CredsURL,
-		DebugLog: c.DebugLog,
+	return s3creds.NewClient(iamClient, &s3creds.Config{
+		BaseURL: c.s3CredsURL(),
 	})
 }
 
+// s3CredsURL returns the configured S3 Credentials URL or the one of the
+// region and environment. It is resolved once so S3CredsURL is never written
+// while the transport or other clients read it
+func (c *Config) s3CredsURL() string {
+	c.s3CredsURLOnce.Do(func() {
+		c.resolvedS3CredsURL = c.S3CredsURL
+		if c.resolvedS3CredsURL != "" || c.Environment == "" || c.Region == "" {
+			return
+		}
+		ac, err := config.New(config.WithRegion(c.Region), config.WithEnv(c.Environment))
+		if err == nil {
+			c.resolvedS3CredsURL = ac.Service("s3creds").URL
+		}
+	})
+	return c.resolvedS3CredsURL
+}
+
 // setupIAMClient sets up an HSDP IAM client
 func (c *Config) setupIAMClient() {
-	standardClient := http.DefaultClient
-	if c.RetryMax > 0 {
-		retryClient := retryablehttp.NewClient()
-		retryClient.RetryMax = 5
-		standardClient = retryClient.StandardClient()
-	}
 	c.iamClient = nil
-	client, err := iam.NewClient(standardClient, &c.Config)
+	// API traffic is logged by our own transport, see debugLogger
+	iamConfig := c.Config
+	iamConfig.DebugLog = ""
+	client, err := iam.NewClient(c.serviceHTTPClient(), &iamConfig)
 	if err != nil {
 		c.iamClientErr = err
 		return
 	}
+	if err = c.loginIAMClient(client); err != nil {
+		c.iamClientErr = err
+		return
+	}
+	c.iamSessions.register(providerSession, client, c.loginIAMClient)
+	c.iamClient = client
+}
+
+// loginIAMClient logs in client with the provider service identity and/or org admin
+func (c *Config) loginIAMClient(client *iam.Client) error {
 	if c.ServiceID != "" && c.ServicePrivateKey != "" {
-		err = client.ServiceLogin(iam.Service{
+		err := client.ServiceLogin(iam.Service{
 			ServiceID:  c.ServiceID,
 			PrivateKey: c.ServicePrivateKey,
 		})
 		if err != nil {
-			c.iamClientErr = err
-			return
+			return err
 		}
 	}
 	if c.OrgAdminUsername != "" && c.OrgAdminPassword != "" {
-		err = client.Login(c.OrgAdminUsername, c.OrgAdminPassword)
-		if err != nil {
-			c.iamClientErr = err
+		return client.Login(c.OrgAdminUsername, c.OrgAdminPassword)
+	}
+	return nil
+}
+
+// stlURL returns the configured STL URL or the one of the region. Like
+// s3CredsURL it is resolved once and never written back to STLURL
+func (c *Config) stlURL() string {
+	c.stlURLOnce.Do(func() {
+		c.resolvedSTLURL = c.STLURL
+		if c.resolvedSTLURL != "" {
 			return
 		}
-	}
-	c.iamClient = client
+		ac, err := config.New(config.WithRegion(c.Region))
+		if err == nil {
+			c.resolvedSTLURL = ac.Service("stl").URL
+		}
+	})
+	return c.resolvedSTLURL
 }
 
 func (c *Config) setupSTLClient() {
-	if c.consoleClientErr != nil {
+	consoleClient, err := c.ConsoleClient()
+	if err != nil {
 		c.stlClient = nil
-		c.stlClientErr = c.consoleClientErr
+		c.stlClientErr = err
 		return
 	}
-	region := c.Region
-	if region == "" {
-		region = "dev"
-	}
-	ac, err := config.New(config.WithRegion(c.Region))
-	if err == nil {
-		if url := ac.Service("stl").URL; c.STLURL == "" {
-			c.STLURL = url
-		}
-	}
-	client, err := stl.NewClient(c.consoleClient, &stl.Config{
-		STLAPIURL: c.STLURL,
-		DebugLog:  c.DebugLog,
+	client, err := stl.NewClient(consoleClient, &stl.Config{
+		STLAPIURL: c.stlURL(),
 	})
 	if err != nil {
 		c.stlClient = nil
@@ -163,22 +286,14 @@ func (c *Config) setupSTLClient() {
 }
 
 func (c *Config) setupS3CredsClient() {
-	if c.iamClientErr != nil {
+	iamClient, err := c.IAMClient()
+	if err != nil {
 		c.s3credsClient = nil
-		c.credsClientErr = c.iamClientErr
+		c.credsClientErr = err
 		return
 	}
-	if c.Environment != "" && c.Region != "" {
-		ac, err := config.New(config.WithRegion(c.Region), config.WithEnv(c.Environment))
-		if err == nil {
-			if url := ac.Service("s3creds").URL; c.S3CredsURL == "" {
-				c.S3CredsURL = url
-			}
-		}
-	}
-	client, err := s3creds.NewClient(c.iamClient, &s3creds.Config{
-		BaseURL:  c.S3CredsURL,
-		DebugLog: c.DebugLog,
+	client, err := s3creds.NewClient(iamClient, &s3creds.Config{
+		BaseURL: c.s3CredsURL(),
 	})
 	if err != nil {
 		c.s3credsClient = nil
@@ -190,14 +305,16 @@ func (c *Config) setupS3CredsClient() {
 
 // setupCartelClient sets up an Cartel client
 func (c *Config) setupCartelClient() {
-	client, err := cartel.NewClient(nil, &cartel.Config{
+	// Cartel has its own certificate verification setting so it gets its own copy of the transport
+	transport := c.transport()
+	transport.TLSClientConfig.InsecureSkipVerify = c.CartelSkipVerify || c.InsecureSkipVerify
+	client, err := cartel.NewClient(c.newHTTPClient(transport), &cartel.Config{
 		Region:     c.Region,
 		Host:       c.CartelHost,
 		Token:      c.CartelToken,
 		Secret:     c.CartelSecret,
 		NoTLS:      c.CartelNoTLS,
 		SkipVerify: c.CartelSkipVerify,
-		DebugLog:   c.DebugLog,
 	})
 	if err != nil {
 		c.cartelClient = nil
@@ -207,12 +324,33 @@ func (c *Config) setupCartelClient() {
 	c.cartelClient = client
 }
 
+// consoleConfig returns the Console configuration. URLs which are not
+// configured are those of the region
+func (c *Config) consoleConfig() *console.Config {
+	consoleConfig := &console.Config{
+		Region:         c.Region,
+		UAAURL:         c.UAAURL,
+		BaseConsoleURL: c.ConsoleURL,
+	}
+	if c.Region == "" || (consoleConfig.UAAURL != "" && consoleConfig.BaseConsoleURL != "") {
+		return consoleConfig
+	}
+	ac, err := config.New(config.WithRegion(c.Region))
+	if err != nil {
+		return consoleConfig
+	}
+	if consoleConfig.UAAURL == "" {
+		consoleConfig.UAAURL = ac.Service("uaa").URL
+	}
+	if consoleConfig.BaseConsoleURL == "" {
+		consoleConfig.BaseConsoleURL = ac.Service("console").URL
+	}
+	return consoleConfig
+}
+
 // setupConsoleClient sets up an Console client
 func (c *Config) setupConsoleClient() {
-	client, err := console.NewClient(nil, &console.Config{
-		Region:   c.Region,
-		DebugLog: c.DebugLog,
-	})
+	client, err := console.NewClient(c.serviceHTTPClient(), c.consoleConfig())
 	if err != nil {
 		c.consoleClient = nil
 		c.consoleClientErr = err
@@ -229,15 +367,15 @@ func (c *Config) setupConsoleClient() {
 }
 
 // getFHIRClientFromEndpoint creates a HSDP CDR client form the given endpoint
-func (c *Config) getFHIRClientFromEndpoint(endpointURL string) (*cdr.Client, error) {
-	if c.iamClientErr != nil {
-		return nil, c.iamClientErr
+func (c *Config) getFHIRClientFromEndpoint(endpointURL string, principal ...*Principal) (*cdr.Client, error) {
+	iamClient, err := c.IAMClient(principal...)
+	if err != nil {
+		return nil, err
 	}
-	client, err := cdr.NewClient(c.iamClient, &cdr.Config{
+	client, err := cdr.NewClient(iamClient, &cdr.Config{
 		CDRURL:    "https://localhost.domain",
 		RootOrgID: "",
 		TimeZone:  c.TimeZone,
-		DebugLog:  c.DebugLog,
 	})
 	if err != nil {
 		return nil, err
@@ -250,17 +388,17 @@ func (c *Config) getFHIRClientFromEndpoint(endpointURL string) (*cdr.Client, err
 
 // getFHIRClient creates a HSDP CDR client
 func (c *Config) getFHIRClient(baseURL, rootOrgID string) (*cdr.Client, error) {
-	if c.iamClientErr != nil {
-		return nil, fmt.Errorf("IAM client error in getFHIRClient: %w", c.iamClientErr)
+	iamClient, err := c.IAMClient()
+	if err != nil {
+		return nil, fmt.Errorf("IAM client error in getFHIRClient: %w", err)
 	}
 	if rootOrgID == "" {
 		return nil, fmt.Errorf("getFHIRClient: %w", ErrMissingOrganizationID)
 	}
-	client, err := cdr.NewClient(c.iamClient, &cdr.Config{
+	client, err := cdr.NewClient(iamClient, &cdr.Config{
 		CDRURL:    baseURL,
 		RootOrgID: rootOrgID,
 		TimeZone:  c.TimeZone,
-		DebugLog:  c.DebugLog,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getFHIRClient: %w", err)
@@ -269,21 +407,37 @@ func (c *Config) getFHIRClient(baseURL, rootOrgID string) (*cdr.Client, error) {
 }
 
 func (c *Config) Debug(format string, a ...interface{}) (int, error) {
-	if c.debugFile != nil {
+	if c.debugLogger != nil {
 		output := fmt.Sprintf(format, a...)
-		return c.debugFile.WriteString(output)
+		c.debugLogger.Log(debugLogEntry{Type: "message", Message: output})
+		return len(output), nil
 	}
 	return 0, nil
 }
 
-func (c *Config) getDICOMConfigClient(url string) (*dicom.Client, error) {
-	if c.iamClientErr != nil {
-		return nil, fmt.Errorf("DICM client error in getDICOMConfigClient: %w", c.iamClientErr)
+// debugFunc writes a formatted message to the debug log
+type debugFunc func(format string, a ...interface{})
+
+// debugFor returns a debugFunc which tags entries with the given resource
+func (c *Config) debugFor(resourceType, id string) debugFunc {
+	return func(format string, a ...interface{}) {
+		c.debugLogger.Log(debugLogEntry{
+			Type:         "message",
+			ResourceType: resourceType,
+			ResourceID:   id,
+			Message:      fmt.Sprintf(format, a...),
+		})
 	}
-	client, err := dicom.NewClient(c.iamClient, &dicom.Config{
+}
+
+func (c *Config) getDICOMConfigClient(url string, principal ...*Principal) (*dicom.Client, error) {
+	iamClient, err := c.IAMClient(principal...)
+	if err != nil {
+		return nil, fmt.Errorf("DICM client error in getDICOMConfigClient: %w", err)
+	}
+	client, err := dicom.NewClient(iamClient, &dicom.Config{
 		DICOMConfigURL: url,
 		TimeZone:       c.TimeZone,
-		DebugLog:       c.DebugLog,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getDICOMConfigClient: %w", err)
@@ -292,18 +446,20 @@ func (c *Config) getDICOMConfigClient(url string) (*dicom.Client, error) {
 }
 
 func (c *Config) setupPKIClient() {
-	if c.iamClientErr != nil {
-		c.pkiClientErr = fmt.Errorf("IAM client error in setupPKIClient: %w", c.iamClientErr)
+	iamClient, err := c.IAMClient()
+	if err != nil {
+		c.pkiClientErr = fmt.Errorf("IAM client error in setupPKIClient: %w", err)
 		return
 	}
-	if c.consoleClientErr != nil {
-		c.pkiClientErr = fmt.Errorf("Console client error in setupPKIClient: %w", c.consoleClientErr)
+	consoleClient, err := c.ConsoleClient()
+	if err != nil {
+		c.pkiClientErr = fmt.Errorf("Console client error in setupPKIClient: %w", err)
 		return
 	}
-	client, err := pki.NewClient(c.consoleClient, c.iamClient, &pki.Config{
+	client, err := pki.NewClient(consoleClient, iamClient, &pki.Config{
 		Region:      c.Region,
 		Environment: c.Environment,
-		DebugLog:    c.DebugLog,
+		PKIURL:      c.PKIURL,
 	})
 	if err != nil {
 		c.pkiClient = nil
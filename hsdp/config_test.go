@@ -1,9 +1,11 @@
 package hsdp
 
 import (
+	"sync"
+	"testing"
+
 	"github.com/philips-software/go-hsdp-api/iam"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestConfig(t *testing.T) {
@@ -21,3 +23,80 @@ func TestConfig(t *testing.T) {
 
 	assert.Equal(t, iam.ErrNotAuthorized, c.iamClientErr)
 }
+
+func TestConfigLazyClients(t *testing.T) {
+	c := &Config{}
+
+	c.Region = "us-east"
+	c.Environment = "client-test"
+
+	_, _ = c.CartelClient()
+	assert.Nil(t, c.iamClient)
+	assert.Nil(t, c.iamClientErr)
+	assert.Nil(t, c.consoleClient)
+}
+
+func TestConfigS3CredsURL(t *testing.T) {
+	c := &Config{Config: iam.Config{
+		Region:      "us-east",
+		Environment: "client-test",
+	}}
+
+	// Derived without setting up the S3 Credentials client, concurrently
+	var wg sync.WaitGroup
+	urls := make([]string, 4)
+	for i := range urls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			urls[i] = c.s3CredsURL()
+		}(i)
+	}
+	wg.Wait()
+	assert.NotEmpty(t, urls[0])
+	for _, url := range urls {
+		assert.Equal(t, urls[0], url)
+	}
+	assert.Empty(t, c.S3CredsURL)
+
+	c = &Config{
+		Config: iam.Config{
+			Region:      "us-east",
+			Environment: "client-test",
+		},
+		S3CredsURL: "https://s3creds.example.com",
+	}
+	assert.Equal(t, "https://s3creds.example.com", c.s3CredsURL())
+
+	c = &Config{}
+	assert.Empty(t, c.s3CredsURL())
+}
+
+func TestConfigSTLURL(t *testing.T) {
+	c := &Config{Config: iam.Config{
+		Region: "us-east",
+	}}
+
+	// Derived without setting up the STL client, concurrently
+	var wg sync.WaitGroup
+	urls := make([]string, 4)
+	for i := range urls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			urls[i] = c.stlURL()
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, "https://console.na1.hsdp.io/api/stl/user/v1/graphql", urls[0])
+	for _, url := range urls {
+		assert.Equal(t, urls[0], url)
+	}
+	assert.Empty(t, c.STLURL)
+
+	c = &Config{
+		Config: iam.Config{Region: "us-east"},
+		STLURL: "https://stl.example.com",
+	}
+	assert.Equal(t, "https://stl.example.com", c.stlURL())
+}
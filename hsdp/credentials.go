@@ -0,0 +1,163 @@
+package hsdp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+// Principal is an IAM identity a resource acts as instead of the provider identity
+type Principal struct {
+	ServiceID         string
+	ServicePrivateKey string
+	Username          string
+	Password          string
+}
+
+func (p *Principal) empty() bool {
+	return p == nil || (p.ServiceID == "" && p.Username == "")
+}
+
+// key identifies the session of the principal in the cache. All fields are
+// hashed so a rotated secret gets a new session, and principals never share
+// the namespace of the provider session
+func (p *Principal) key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		p.ServiceID, p.ServicePrivateKey, p.Username, p.Password,
+	}, "\x00")))
+	return "principal:" + hex.EncodeToString(sum[:])
+}
+
+func (p *Principal) login(client *iam.Client) error {
+	if p.ServiceID != "" {
+		return client.ServiceLogin(iam.Service{
+			ServiceID:  p.ServiceID,
+			PrivateKey: p.ServicePrivateKey,
+		})
+	}
+	return client.Login(p.Username, p.Password)
+}
+
+// credentialsSchema is the optional block resources use to override the provider identity
+func credentialsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"service_id": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					RequiredWith:  []string{"credentials.0.service_private_key"},
+					ConflictsWith: []string{"credentials.0.username"},
+				},
+				"service_private_key": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Sensitive:    true,
+					RequiredWith: []string{"credentials.0.service_id"},
+				},
+				"username": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					RequiredWith:  []string{"credentials.0.password"},
+					ConflictsWith: []string{"credentials.0.service_id"},
+				},
+				"password": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Sensitive:    true,
+					RequiredWith: []string{"credentials.0.username"},
+				},
+			},
+		},
+	}
+}
+
+// principalFromResourceData returns the principal of the credentials block, if any
+func principalFromResourceData(d *schema.ResourceData) *Principal {
+	list, ok := d.Get("credentials").([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	block := list[0].(map[string]interface{})
+	principal := &Principal{}
+	principal.ServiceID, _ = block["service_id"].(string)
+	principal.ServicePrivateKey, _ = block["service_private_key"].(string)
+	principal.Username, _ = block["username"].(string)
+	principal.Password, _ = block["password"].(string)
+	if principal.empty() {
+		return nil
+	}
+	return principal
+}
+
+// principalClients caches the IAM clients of principals for the lifetime of the provider
+type principalClients struct {
+	sync.Mutex
+	clients map[string]*principalClient
+}
+
+// principalClient is the login of a single principal. Only callers acting as
+// the same principal wait for it
+type principalClient struct {
+	once   sync.Once
+	client *iam.Client
+	err    error
+}
+
+// principalIAMClient returns a logged in IAM client for principal. A failed
+// login is not cached so the next resource tries again
+func (c *Config) principalIAMClient(principal *Principal) (*iam.Client, error) {
+	key := principal.key()
+	c.principalClients.Lock()
+	if c.principalClients.clients == nil {
+		c.principalClients.clients = make(map[string]*principalClient)
+	}
+	entry, ok := c.principalClients.clients[key]
+	if !ok {
+		entry = &principalClient{}
+		c.principalClients.clients[key] = entry
+	}
+	c.principalClients.Unlock()
+
+	entry.once.Do(func() {
+		entry.client, entry.err = c.loginPrincipal(key, principal)
+	})
+	if entry.err != nil {
+		c.principalClients.Lock()
+		if c.principalClients.clients[key] == entry {
+			delete(c.principalClients.clients, key)
+		}
+		c.principalClients.Unlock()
+	}
+	return entry.client, entry.err
+}
+
+func (c *Config) loginPrincipal(key string, principal *Principal) (*iam.Client, error) {
+	// API traffic is logged by our own transport, see debugLogger
+	iamConfig := c.Config
+	iamConfig.DebugLog = ""
+	client, err := iam.NewClient(c.serviceHTTPClient(), &iamConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := principal.login(client); err != nil {
+		return nil, err
+	}
+	c.iamSessions.register(key, client, principal.login)
+	return client, nil
+}
+
+// updateCredentialsOnly is the update function of resources which only support
+// changing their credentials block. The new credentials are used from the next call on
+func updateCredentialsOnly(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	return nil
+}
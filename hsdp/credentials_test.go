@@ -0,0 +1,68 @@
+package hsdp
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/philips-software/go-hsdp-api/iam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalKey(t *testing.T) {
+	alice := &Principal{Username: "alice", Password: "one"}
+
+	assert.Equal(t, alice.key(), (&Principal{Username: "alice", Password: "one"}).key())
+	assert.NotEqual(t, alice.key(), (&Principal{Username: "alice", Password: "two"}).key())
+	assert.NotEqual(t, alice.key(), (&Principal{ServiceID: "alice", ServicePrivateKey: "one"}).key())
+	assert.NotEqual(t, providerSession, (&Principal{Username: "provider"}).key())
+	assert.NotContains(t, alice.key(), "one")
+}
+
+func TestPrincipalIAMClient(t *testing.T) {
+	f := newFakeHSDP(t)
+	c := &Config{Config: iam.Config{
+		IAMURL:         f.URL,
+		IDMURL:         f.URL,
+		OAuth2ClientID: "client",
+		OAuth2Secret:   "secret",
+	}}
+	tokens := func() int {
+		f.Lock()
+		defer f.Unlock()
+		return f.tokens
+	}
+
+	// Concurrent resources acting as the same principal share one login
+	var wg sync.WaitGroup
+	clients := make([]*iam.Client, 8)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = c.principalIAMClient(&Principal{Username: "alice", Password: "one"})
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, tokens())
+	for _, client := range clients {
+		assert.NotNil(t, client)
+		assert.Same(t, clients[0], client)
+	}
+
+	// A rotated password is a new principal and logs in again
+	rotated, err := c.principalIAMClient(&Principal{Username: "alice", Password: "two"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.NotSame(t, clients[0], rotated)
+	assert.Equal(t, 2, tokens())
+
+	// Failed logins are not cached
+	_, err = c.principalIAMClient(&Principal{Username: "bob", Password: "wrong"})
+	assert.NotNil(t, err)
+	_, err = c.principalIAMClient(&Principal{Username: "bob", Password: "wrong"})
+	assert.NotNil(t, err)
+	c.principalClients.Lock()
+	assert.Len(t, c.principalClients.clients, 2)
+	c.principalClients.Unlock()
+}
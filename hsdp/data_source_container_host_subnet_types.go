@@ -44,9 +44,9 @@ func dataSourceContainerHostSubnetTypesRead(ctx context.Context, d *schema.Resou
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	details, _, err := client.GetAllSubnets()
+	details, resp, err := client.GetAllSubnets()
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	ids := make(map[string]interface{})
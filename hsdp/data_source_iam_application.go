@@ -44,12 +44,12 @@ func dataSourceIAMApplicationRead(_ context.Context, d *schema.ResourceData, met
 	propID := d.Get("proposition_id").(string)
 	name := d.Get("name").(string)
 
-	apps, _, err := client.Applications.GetApplications(&iam.GetApplicationsOptions{
+	apps, resp, err := client.Applications.GetApplications(&iam.GetApplicationsOptions{
 		PropositionID: &propID,
 		Name:          &name,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if len(apps) == 0 {
 		return diag.FromErr(ErrResourceNotFound)
@@ -0,0 +1,176 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+func dataSourceIAMEffectivePermissions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMEffectivePermissionsRead,
+		Schema: map[string]*schema.Schema{
+			"member_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"member_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      memberTypeUser,
+				ValidateFunc: validation.StringInSlice([]string{memberTypeUser, memberTypeService}, false),
+			},
+			"organization_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"required_permissions": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"permissions": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"grants": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"permission": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"group_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"group_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"role_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"role_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+}
+
+// permissionGrant is the group and role path which grants a permission
+type permissionGrant struct {
+	Permission string
+	Group      iam.Group
+	Role       iam.Role
+}
+
+// memberGroups returns the groups of organizationID which memberID is a member of
+func memberGroups(ctx context.Context, config *Config, client *iam.Client, memberType, memberID, organizationID string) ([]iam.Group, *http.Response, error) {
+	return searchGroups(ctx, config, client, url.Values{
+		"organizationId": {organizationID},
+		"memberType":     {strings.ToUpper(memberType)},
+		"memberId":       {memberID},
+	})
+}
+
+// effectivePermissions resolves the permissions granted through the roles of groups
+func effectivePermissions(client *iam.Client, groups []iam.Group) ([]permissionGrant, *iam.Response, error) {
+	var grants []permissionGrant
+	for _, group := range groups {
+		roles, resp, err := client.Groups.GetRoles(group)
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, role := range *roles {
+			permissions, resp, err := client.Roles.GetRolePermissions(role)
+			if err != nil {
+				return nil, resp, err
+			}
+			for _, p := range *permissions {
+				grants = append(grants, permissionGrant{Permission: p, Group: group, Role: role})
+			}
+		}
+	}
+	sort.SliceStable(grants, func(i, j int) bool {
+		if grants[i].Permission != grants[j].Permission {
+			return grants[i].Permission < grants[j].Permission
+		}
+		return grants[i].Group.Name < grants[j].Group.Name
+	})
+	return grants, nil, nil
+}
+
+func dataSourceIAMEffectivePermissionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+	ctx = withResource(ctx, "data.hsdp_iam_effective_permissions", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	memberID := d.Get("member_id").(string)
+	memberType := d.Get("member_type").(string)
+	organizationID := d.Get("organization_id").(string)
+
+	groups, groupsResp, err := memberGroups(ctx, config, client, memberType, memberID, organizationID)
+	if err != nil {
+		return diagFromResponse(err, groupsResp)
+	}
+	grants, resp, err := effectivePermissions(client, groups)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	var permissions []string
+	granted := make(map[string]bool)
+	list := make([]interface{}, len(grants))
+	for i, g := range grants {
+		if !granted[g.Permission] {
+			granted[g.Permission] = true
+			permissions = append(permissions, g.Permission)
+		}
+		list[i] = map[string]interface{}{
+			"permission": g.Permission,
+			"group_id":   g.Group.ID,
+			"group_name": g.Group.Name,
+			"role_id":    g.Role.ID,
+			"role_name":  g.Role.Name,
+		}
+	}
+
+	required := expandStringList(d.Get("required_permissions").(*schema.Set).List())
+	if missing := difference(required, permissions); len(missing) > 0 {
+		sort.Strings(missing)
+		return append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("%s %s is missing required permissions in organization %s", memberType, memberID, organizationID),
+			Detail:        "Missing permissions: " + strings.Join(missing, ", "),
+			AttributePath: cty.GetAttrPath("required_permissions"),
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", organizationID, memberID))
+	_ = d.Set("permissions", permissions)
+	_ = d.Set("grants", list)
+	return diags
+}
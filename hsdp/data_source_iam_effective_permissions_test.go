@@ -0,0 +1,102 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMEffectivePermissionsConfig(f *fakeHSDP, required []string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_role" "reader" {
+  name                  = "READER"
+  description           = "Reader"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ", "ROLE.READ"]
+}
+
+resource "hsdp_iam_role" "writer" {
+  name                  = "WRITER"
+  description           = "Writer"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ", "GROUP.WRITE"]
+}
+
+resource "hsdp_iam_group" "readers" {
+  name                  = "Readers"
+  description           = "Readers"
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.reader.id]
+  users                 = ["user-1"]
+}
+
+resource "hsdp_iam_group" "writers" {
+  name                  = "Writers"
+  description           = "Writers"
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.writer.id]
+  users                 = ["user-1"]
+}
+
+data "hsdp_iam_effective_permissions" "user" {
+  member_id            = "user-1"
+  organization_id      = "fake-root-org"
+  required_permissions = %s
+
+  depends_on = [hsdp_iam_group.readers, hsdp_iam_group.writers]
+}
+`, hclStringList(required))
+}
+
+func TestDataSourceIAMEffectivePermissions(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMEffectivePermissionsConfig(f, []string{"GROUP.WRITE"}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_iam_effective_permissions.user", "permissions.#", "3"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_effective_permissions.user", "grants.#", "4"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.hsdp_iam_effective_permissions.user", "grants.*", map[string]string{
+						"permission": "GROUP.WRITE",
+						"group_name": "Writers",
+						"role_name":  "WRITER",
+					}),
+				),
+			},
+			{
+				Config:      testIAMEffectivePermissionsConfig(f, []string{"GROUP.WRITE", "ROLE.WRITE"}),
+				ExpectError: regexp.MustCompile(`Missing permissions: ROLE.WRITE`),
+			},
+		},
+	})
+}
+
+func TestMemberGroupsPaged(t *testing.T) {
+	f := newFakeHSDP(t)
+	for i := 0; i < groupsPageSize+20; i++ {
+		f.put("Group", fakeObject{
+			"name":                 fmt.Sprintf("group-%03d", i),
+			"managingOrganization": "fake-root-org",
+			"_users":               []string{"user-1"},
+		})
+	}
+	f.put("Group", fakeObject{"name": "other", "managingOrganization": "fake-root-org", "_users": []string{"user-2"}})
+	config := f.config()
+	client, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	groups, _, err := memberGroups(context.Background(), config, client, "user", "user-1", "fake-root-org")
+	assert.Nil(t, err)
+	assert.Equal(t, groupsPageSize+20, len(groups))
+	assert.Equal(t, 2, f.requested(http.MethodGet, "/authorize/identity/Group"))
+}
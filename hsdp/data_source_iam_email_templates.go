@@ -0,0 +1,184 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// emailPlaceholderPattern matches IAM placeholders like {{user.givenName}}
+var emailPlaceholderPattern = regexp.MustCompile(`{{\s*([A-Za-z0-9_.]+)\s*}}`)
+
+// emailPlaceholderSamples are the values used to preview IAM placeholders
+var emailPlaceholderSamples = map[string]string{
+	"user.email":                  "jane.doe@example.com",
+	"user.userName":               "jdoe",
+	"user.givenName":              "Jane",
+	"user.familyName":             "Doe",
+	"user.lockoutPeriod":          "30",
+	"link.verification":           "https://iam.example.com/verify?otp=123456",
+	"link.passwordReset":          "https://iam.example.com/reset?otp=123456",
+	"link.passwordChange":         "https://iam.example.com/change-password",
+	"template.linkExpiryPeriod":   "24",
+	"password.expiresAfterPeriod": "7",
+}
+
+// renderEmailTemplate replaces the placeholders in text with values. Unknown
+// placeholders are left as is so they stand out in the preview
+func renderEmailTemplate(text string, values map[string]string) string {
+	return emailPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := emailPlaceholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func dataSourceIAMEmailTemplates() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMEmailTemplatesRead,
+		Schema: map[string]*schema.Schema{
+			"managing_organization": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"locale": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"placeholders": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"templates": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"locale": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"format": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"from": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subject": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"link": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"preview_subject": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"preview_message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceIAMEmailTemplatesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	orgID := d.Get("managing_organization").(string)
+	query := url.Values{"organizationId": {orgID}}
+	if v, ok := d.GetOk("type"); ok {
+		query.Set("type", v.(string))
+	}
+	if v, ok := d.GetOk("locale"); ok {
+		query.Set("locale", v.(string))
+	}
+	templates, resp, err := searchEmailTemplates(ctx, config, client, query)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+
+	values := make(map[string]string, len(emailPlaceholderSamples))
+	for k, v := range emailPlaceholderSamples {
+		values[k] = v
+	}
+	for k, v := range d.Get("placeholders").(map[string]interface{}) {
+		values[k] = v.(string)
+	}
+
+	var list []interface{}
+	var withoutMessage []string
+	for _, t := range templates {
+		// IAM does not always return the message of a template, there is
+		// nothing to preview then
+		if t.Message == "" {
+			withoutMessage = append(withoutMessage, t.ID)
+		}
+		// Messages are stored base64 encoded, older templates may not be
+		message := t.Message
+		if decoded, err := base64.StdEncoding.DecodeString(t.Message); err == nil {
+			message = string(decoded)
+		}
+		list = append(list, map[string]interface{}{
+			"id":              t.ID,
+			"type":            t.Type,
+			"locale":          t.Locale,
+			"format":          t.Format,
+			"from":            t.From,
+			"subject":         t.Subject,
+			"link":            t.Link,
+			"message":         message,
+			"preview_subject": renderEmailTemplate(t.Subject, values),
+			"preview_message": renderEmailTemplate(message, values),
+		})
+	}
+	if len(withoutMessage) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "email template message not returned",
+			Detail: fmt.Sprintf("IAM did not return the message of template %s, its message and preview_message are empty",
+				strings.Join(withoutMessage, ", ")),
+		})
+	}
+	d.SetId(orgID)
+	_ = d.Set("templates", list)
+	return diags
+}
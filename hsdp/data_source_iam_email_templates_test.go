@@ -0,0 +1,50 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSourceIAMEmailTemplatesWithoutMessage(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.put("EmailTemplate", fakeObject{
+		"type":                 "PASSWORD_CHANGED",
+		"locale":               "en-US",
+		"subject":              "Hi {{user.givenName}}",
+		"message":              base64.StdEncoding.EncodeToString([]byte("Dear {{user.givenName}}")),
+		"managingOrganization": "fake-root-org",
+	})
+	// The message is left out of the response, as IAM may do
+	withoutMessage := f.put("EmailTemplate", fakeObject{
+		"type":                 "ACCOUNT_VERIFICATION",
+		"locale":               "en-US",
+		"subject":              "Welcome {{user.givenName}}",
+		"managingOrganization": "fake-root-org",
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceIAMEmailTemplates().Schema, map[string]interface{}{
+		"managing_organization": "fake-root-org",
+	})
+	diags := dataSourceIAMEmailTemplatesRead(context.Background(), d, f.config())
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, withoutMessage)
+	}
+	previews := map[string]string{}
+	for _, v := range d.Get("templates").([]interface{}) {
+		template := v.(map[string]interface{})
+		previews[template["subject"].(string)] = template["preview_message"].(string)
+		if template["id"] == withoutMessage {
+			assert.Equal(t, "", template["message"])
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"Hi {{user.givenName}}":      "Dear Jane",
+		"Welcome {{user.givenName}}": "",
+	}, previews)
+}
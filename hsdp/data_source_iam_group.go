@@ -0,0 +1,102 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+func dataSourceIAMGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMGroupRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"managing_organization": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"roles": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"users": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"services": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+
+}
+
+func dataSourceIAMGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+	ctx = withResource(ctx, "data.hsdp_iam_group", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	name := d.Get("name").(string)
+	managingOrganization := d.Get("managing_organization").(string)
+
+	groups, httpResp, err := searchGroups(ctx, config, client, url.Values{
+		"name":           {name},
+		"organizationId": {managingOrganization},
+	})
+	if err != nil {
+		return diagFromResponse(err, httpResp)
+	}
+	var group *iam.Group
+	for i, g := range groups {
+		if g.Name == name && g.ManagingOrganization == managingOrganization {
+			group = &groups[i]
+			break
+		}
+	}
+	if group == nil {
+		return diag.FromErr(fmt.Errorf("group %q in organization %s: %w", name, managingOrganization, ErrResourceNotFound))
+	}
+	roles, resp, err := client.Groups.GetRoles(*group)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	roleIDs := make([]string, len(*roles))
+	for i, r := range *roles {
+		roleIDs[i] = r.ID
+	}
+	users, resp, err := groupUsers(client, group.ID)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	services, httpResp, err := groupServices(ctx, config, client, group.ID)
+	if err != nil {
+		return diagFromResponse(err, httpResp)
+	}
+
+	d.SetId(group.ID)
+	_ = d.Set("description", group.Description)
+	_ = d.Set("roles", roleIDs)
+	_ = d.Set("users", users)
+	_ = d.Set("services", services)
+	return diags
+}
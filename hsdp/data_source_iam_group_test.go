@@ -0,0 +1,121 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMGroupDataSourceConfig(f *fakeHSDP) string {
+	return f.providerConfig() + `
+resource "hsdp_iam_role" "test" {
+  name                  = "TEST_LOOKUP_ROLE"
+  description           = "Lookup role"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ", "ROLE.READ"]
+}
+
+resource "hsdp_iam_group" "test" {
+  name                  = "LookupGroup"
+  description           = "Lookup group"
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.test.id]
+  users                 = ["user-1", "user-2"]
+}
+
+data "hsdp_iam_role" "test" {
+  name                  = hsdp_iam_role.test.name
+  managing_organization = "fake-root-org"
+}
+
+data "hsdp_iam_group" "test" {
+  name                  = hsdp_iam_group.test.name
+  managing_organization = "fake-root-org"
+}
+`
+}
+
+func TestDataSourceIAMRoleAndGroup(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupDataSourceConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.hsdp_iam_role.test", "id", "hsdp_iam_role.test", "id"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_role.test", "description", "Lookup role"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_role.test", "permissions.#", "2"),
+					resource.TestCheckResourceAttrPair("data.hsdp_iam_group.test", "id", "hsdp_iam_group.test", "id"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_group.test", "roles.#", "1"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_group.test", "users.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceIAMGroupServicesPaged(t *testing.T) {
+	for _, omitTotal := range []bool{false, true} {
+		t.Run(fmt.Sprintf("omitTotal=%t", omitTotal), func(t *testing.T) {
+			f := newFakeHSDP(t)
+			f.omitTotal = omitTotal
+			services := make([]string, servicesPageSize+20)
+			for i := range services {
+				services[i] = fmt.Sprintf("service-%03d", i)
+			}
+			f.put("Group", fakeObject{
+				"name":                 "ServiceGroup",
+				"managingOrganization": "fake-root-org",
+				"_services":            services,
+			})
+
+			resource.UnitTest(t, resource.TestCase{
+				ProviderFactories: f.providerFactories(),
+				Steps: []resource.TestStep{
+					{
+						Config: f.providerConfig() + `
+data "hsdp_iam_group" "test" {
+  name                  = "ServiceGroup"
+  managing_organization = "fake-root-org"
+}
+`,
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.hsdp_iam_group.test", "services.#", fmt.Sprint(len(services))),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSourceIAMGroupOtherOrganization(t *testing.T) {
+	f := newFakeHSDP(t)
+	// Names are only unique within an organization
+	f.put("Group", fakeObject{"name": "Admins", "managingOrganization": "other-org"})
+	id := f.put("Group", fakeObject{"name": "Admins", "managingOrganization": "fake-root-org"})
+	config := f.config()
+
+	d := schema.TestResourceDataRaw(t, dataSourceIAMGroup().Schema, map[string]interface{}{
+		"name":                  "Admins",
+		"managing_organization": "fake-root-org",
+	})
+	diags := dataSourceIAMGroupRead(context.Background(), d, config)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, id, d.Id())
+
+	d = schema.TestResourceDataRaw(t, dataSourceIAMGroup().Schema, map[string]interface{}{
+		"name":                  "Admins",
+		"managing_organization": "third-org",
+	})
+	diags = dataSourceIAMGroupRead(context.Background(), d, config)
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, "not found")
+	}
+}
@@ -3,27 +3,104 @@ package hsdp
 import (
 	"context"
 	"encoding/json"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// tokenIntrospection is the response of the IAM OAuth2 introspect endpoint
+type tokenIntrospection struct {
+	Active        bool   `json:"active"`
+	Scope         string `json:"scope"`
+	Username      string `json:"username"`
+	ClientID      string `json:"client_id"`
+	Subject       string `json:"sub"`
+	TokenType     string `json:"token_type"`
+	IdentityType  string `json:"identity_type"`
+	Expires       int64  `json:"exp"`
+	Organizations struct {
+		ManagingOrganization string `json:"managingOrganization"`
+		OrganizationList     []struct {
+			OrganizationID   string   `json:"organizationId"`
+			OrganizationName string   `json:"organizationName"`
+			Permissions      []string `json:"permissions"`
+		} `json:"organizationList"`
+	} `json:"organizations"`
+}
+
 func dataSourceIAMIntrospect() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceIAMIntrospectRead,
 		Schema: map[string]*schema.Schema{
 			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Computed: true,
 			},
 			"username": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"subject": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"client_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"identity_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"token_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"scopes": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"managing_organization": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"organizations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"organization_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"organization_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"permissions": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
 			"introspect": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -33,31 +110,97 @@ func dataSourceIAMIntrospect() *schema.Resource {
 
 }
 
+// introspectToken introspects token with the OAuth2 client credentials of the
+// provider, so tokens of other users and services can be inspected
+func (c *Config) introspectToken(ctx context.Context, token string) (*tokenIntrospection, []byte, error) {
+	if c.OAuth2ClientID == "" {
+		return nil, nil, fmt.Errorf("%w: set oauth2_client_id and oauth2_password to introspect tokens", ErrMissingClientID)
+	}
+	iamURL, err := c.iamURL()
+	if err != nil {
+		return nil, nil, err
+	}
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, iamURL+"/authorize/oauth2/introspect",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.SetBasicAuth(c.OAuth2ClientID, c.OAuth2Secret)
+	req.Header.Set("Api-Version", "4")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		code, message := parseErrorBody(data)
+		return nil, nil, fmt.Errorf("introspect: %s: %s %s", resp.Status, code, message)
+	}
+	var introspection tokenIntrospection
+	if err := json.Unmarshal(data, &introspection); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return &introspection, data, nil
+}
+
 func dataSourceIAMIntrospectRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	config := meta.(*Config)
+	ctx = withResource(ctx, "data.hsdp_iam_introspect", d.Id())
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
-	if err != nil {
-		return diag.FromErr(err)
+	// Without a token the session of the provider is introspected. Its token
+	// is never written to state
+	token := d.Get("token").(string)
+	if token == "" {
+		client, err := config.IAMClient()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		token = client.Token()
 	}
 
-	resp, _, err := client.Introspect()
-
+	introspection, data, err := config.introspectToken(ctx, token)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	introspectJSON, err := json.Marshal(&resp)
-	if err != nil {
-		return diag.FromErr(err)
+	organizations := make([]interface{}, len(introspection.Organizations.OrganizationList))
+	for i, org := range introspection.Organizations.OrganizationList {
+		organizations[i] = map[string]interface{}{
+			"organization_id":   org.OrganizationID,
+			"organization_name": org.OrganizationName,
+			"permissions":       org.Permissions,
+		}
+	}
+	expiresAt := ""
+	if introspection.Expires > 0 {
+		expiresAt = time.Unix(introspection.Expires, 0).UTC().Format(time.RFC3339)
 	}
 
-	d.SetId(resp.Username)
-	_ = d.Set("managing_organization", resp.Organizations.ManagingOrganization)
-	_ = d.Set("username", resp.Username)
-	_ = d.Set("token", client.Token())
-	_ = d.Set("introspect", string(introspectJSON))
+	id := introspection.Subject
+	if id == "" {
+		id = firstNonEmpty(introspection.Username, introspection.ClientID, "inactive")
+	}
+	d.SetId(id)
+	_ = d.Set("active", introspection.Active)
+	_ = d.Set("username", introspection.Username)
+	_ = d.Set("subject", introspection.Subject)
+	_ = d.Set("client_id", introspection.ClientID)
+	_ = d.Set("identity_type", introspection.IdentityType)
+	_ = d.Set("token_type", introspection.TokenType)
+	_ = d.Set("scopes", strings.Fields(introspection.Scope))
+	_ = d.Set("expires_at", expiresAt)
+	_ = d.Set("managing_organization", introspection.Organizations.ManagingOrganization)
+	_ = d.Set("organizations", organizations)
+	_ = d.Set("introspect", string(data))
 
 	return diags
 }
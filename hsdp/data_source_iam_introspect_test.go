@@ -0,0 +1,45 @@
+package hsdp
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestDataSourceIAMIntrospectToken(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.providerConfig() + `
+data "hsdp_iam_introspect" "provider" {}
+
+data "hsdp_iam_introspect" "ci" {
+  token = "fake-service-token"
+}
+
+data "hsdp_iam_introspect" "expired" {
+  token = "fake-expired-token"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.provider", "username", "admin"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.provider", "managing_organization", "fake-root-org"),
+					resource.TestCheckNoResourceAttr("data.hsdp_iam_introspect.provider", "token"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "active", "true"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "token", "fake-service-token"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "identity_type", "Service"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "subject", "ci@deploy.fake-application.example.com"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "expires_at", "2100-01-01T00:00:00Z"),
+					resource.TestCheckTypeSetElemAttr("data.hsdp_iam_introspect.ci", "scopes.*", "tdr.contract"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "organizations.#", "2"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "organizations.1.organization_id", "fake-child-org"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.ci", "organizations.1.permissions.#", "2"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_introspect.expired", "active", "false"),
+				),
+			},
+		},
+	})
+}
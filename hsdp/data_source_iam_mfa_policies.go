@@ -0,0 +1,124 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceIAMMFAPolicies() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMMFAPoliciesRead,
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"required_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"active_types": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"policies": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceIAMMFAPoliciesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+	ctx = withResource(ctx, "data.hsdp_iam_mfa_policies", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	organization := d.Get("organization").(string)
+
+	policies, resp, err := orgMFAPolicies(ctx, config, client, organization)
+	if err != nil {
+		return diagFromResponse(err, resp, "organization")
+	}
+	var activeTypes []string
+	list := make([]interface{}, len(policies))
+	for i, policy := range policies {
+		policyType := ""
+		if len(policy.Types) > 0 {
+			policyType = policy.Types[0]
+		}
+		active := policy.Active != nil && *policy.Active
+		if active && policyType != "" {
+			activeTypes = append(activeTypes, policyType)
+		}
+		version := ""
+		if policy.Meta != nil {
+			version = policy.Meta.Version
+		}
+		list[i] = map[string]interface{}{
+			"id":          policy.ID,
+			"name":        policy.Name,
+			"description": policy.Description,
+			"type":        policyType,
+			"active":      active,
+			"version":     version,
+		}
+	}
+
+	required := expandStringList(d.Get("required_types").(*schema.Set).List())
+	if missing := difference(required, activeTypes); len(missing) > 0 {
+		sort.Strings(missing)
+		return append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("organization %s does not have the required MFA policies", organization),
+			Detail:        "Missing active MFA types: " + strings.Join(missing, ", "),
+			AttributePath: cty.GetAttrPath("required_types"),
+		})
+	}
+
+	d.SetId(organization)
+	_ = d.Set("active_types", activeTypes)
+	_ = d.Set("policies", list)
+	return diags
+}
@@ -0,0 +1,140 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+const orgTreePageSize = 100
+
+func dataSourceIAMOrgTree() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMOrgTreeRead,
+		Schema: map[string]*schema.Schema{
+			"organization_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntBetween(1, 20),
+			},
+			"organizations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"external_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"parent_org_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"depth": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+}
+
+// childOrganizations pages through the direct sub-organizations of parentID.
+// go-hsdp-api only looks up a single organization so the SCIM search is done directly
+func childOrganizations(ctx context.Context, config *Config, client *iam.Client, parentID string) ([]iam.Organization, *http.Response, error) {
+	var children []iam.Organization
+	for startIndex := 1; ; startIndex += orgTreePageSize {
+		query := url.Values{
+			"filter":     {fmt.Sprintf("parent.value eq %q", parentID)},
+			"count":      {strconv.Itoa(orgTreePageSize)},
+			"startIndex": {strconv.Itoa(startIndex)},
+		}
+		var list struct {
+			TotalResults int                `json:"totalResults"`
+			Resources    []iam.Organization `json:"Resources"`
+		}
+		resp, err := config.iamRequestVersion(ctx, client, "2", http.MethodGet, "/authorize/scim/v2/Organizations?"+query.Encode(), nil, &list)
+		if err != nil {
+			return nil, resp, err
+		}
+		children = append(children, list.Resources...)
+		if len(list.Resources) < orgTreePageSize || len(children) >= list.TotalResults {
+			return children, resp, nil
+		}
+	}
+}
+
+func dataSourceIAMOrgTreeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+	ctx = withResource(ctx, "data.hsdp_iam_org_tree", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	orgID := d.Get("organization_id").(string)
+	maxDepth := d.Get("max_depth").(int)
+
+	// Walk the tree breadth first so parents are listed before their children
+	var organizations []interface{}
+	visited := map[string]bool{orgID: true}
+	level := []string{orgID}
+	for depth := 1; depth <= maxDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, parentID := range level {
+			children, resp, err := childOrganizations(ctx, config, client, parentID)
+			if err != nil {
+				return diagFromResponse(err, resp)
+			}
+			for _, org := range children {
+				if visited[org.ID] {
+					continue
+				}
+				visited[org.ID] = true
+				next = append(next, org.ID)
+				organizations = append(organizations, map[string]interface{}{
+					"id":            org.ID,
+					"name":          org.Name,
+					"type":          org.Type,
+					"external_id":   org.ExternalID,
+					"parent_org_id": parentID,
+					"depth":         depth,
+				})
+			}
+		}
+		level = next
+	}
+
+	d.SetId(orgID)
+	_ = d.Set("organizations", organizations)
+	return diags
+}
@@ -0,0 +1,52 @@
+package hsdp
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestDataSourceIAMOrgTree(t *testing.T) {
+	f := newFakeHSDP(t)
+	org := func(id, name, parent string) {
+		f.put("Organizations", fakeObject{
+			"id":         id,
+			"name":       name,
+			"type":       "Hospital",
+			"externalId": "ext-" + id,
+			"parent":     fakeObject{"value": parent},
+		})
+	}
+	org("tenant-a", "TenantA", "fake-root-org")
+	org("tenant-b", "TenantB", "fake-root-org")
+	org("site-a1", "SiteA1", "tenant-a")
+	org("ward-a1", "WardA1", "site-a1")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.providerConfig() + `
+data "hsdp_iam_org_tree" "all" {
+  organization_id = "fake-root-org"
+}
+
+data "hsdp_iam_org_tree" "shallow" {
+  organization_id = "fake-root-org"
+  max_depth       = 2
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_iam_org_tree.all", "organizations.#", "4"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.hsdp_iam_org_tree.all", "organizations.*", map[string]string{
+						"id":            "ward-a1",
+						"name":          "WardA1",
+						"parent_org_id": "site-a1",
+						"depth":         "3",
+					}),
+					resource.TestCheckResourceAttr("data.hsdp_iam_org_tree.shallow", "organizations.#", "3"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,180 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+func dataSourceIAMPasswordPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMPasswordPolicyRead,
+		Schema: map[string]*schema.Schema{
+			"managing_organization": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"required_complexity": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min_length": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"min_numerics": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"min_uppercase": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"min_lowercase": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+						"min_special_chars": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"expiry_period_in_days": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"history_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"complexity": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min_length": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"max_length": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_numerics": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_uppercase": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_lowercase": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_special_chars": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"challenges_enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"challenge_policy": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"default_questions": {
+							Type:     schema.TypeSet,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"min_question_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"min_answer_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"max_incorrect_attempts": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// weakComplexity lists the complexity rules of policy below the required minimums
+func weakComplexity(policy *iam.PasswordPolicy, required map[string]interface{}) []string {
+	actual := map[string]int{
+		"min_length":        policy.Complexity.MinLength,
+		"min_numerics":      policy.Complexity.MinNumerics,
+		"min_uppercase":     policy.Complexity.MinUpperCase,
+		"min_lowercase":     policy.Complexity.MinLowerCase,
+		"min_special_chars": policy.Complexity.MinSpecialChars,
+	}
+	var weak []string
+	for _, rule := range []string{"min_length", "min_numerics", "min_uppercase", "min_lowercase", "min_special_chars"} {
+		if minimum, _ := required[rule].(int); actual[rule] < minimum {
+			weak = append(weak, fmt.Sprintf("%s is %d, required %d", rule, actual[rule], minimum))
+		}
+	}
+	return weak
+}
+
+func dataSourceIAMPasswordPolicyRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	orgID := d.Get("managing_organization").(string)
+
+	policies, resp, err := client.PasswordPolicies.GetPasswordPolicies(&iam.GetPasswordPolicyOptions{
+		OrganizationID: &orgID,
+	})
+	if err != nil {
+		return diagFromResponse(err, resp, "managing_organization")
+	}
+	if policies == nil || len(*policies) == 0 {
+		return diag.FromErr(fmt.Errorf("%w: organization %s has no password policy", ErrResourceNotFound, orgID))
+	}
+	policy := (*policies)[0]
+
+	if v, ok := d.GetOk("required_complexity"); ok {
+		if required, ok := v.([]interface{})[0].(map[string]interface{}); ok {
+			if weak := weakComplexity(&policy, required); len(weak) > 0 {
+				return append(diags, diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       fmt.Sprintf("password policy of organization %s does not meet the required complexity", orgID),
+					Detail:        strings.Join(weak, "\n"),
+					AttributePath: cty.GetAttrPath("required_complexity"),
+				})
+			}
+		}
+	}
+
+	d.SetId(policy.ID)
+	passwordPolicyToResourceData(&policy, d)
+	return diags
+}
@@ -44,12 +44,12 @@ func dataSourceIAMPropositionRead(ctx context.Context, d *schema.ResourceData, m
 	orgId := d.Get("organization_id").(string)
 	name := d.Get("name").(string)
 
-	prop, _, err := client.Propositions.GetProposition(&iam.GetPropositionsOptions{
+	prop, resp, err := client.Propositions.GetProposition(&iam.GetPropositionsOptions{
 		OrganizationID: &orgId,
 		Name:           &name,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	d.SetId(prop.ID)
@@ -0,0 +1,76 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+func dataSourceIAMRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMRoleRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"managing_organization": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"permissions": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+
+}
+
+func dataSourceIAMRoleRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	name := d.Get("name").(string)
+	managingOrganization := d.Get("managing_organization").(string)
+
+	roles, resp, err := client.Roles.GetRoles(&iam.GetRolesOptions{
+		Name:           &name,
+		OrganizationID: &managingOrganization,
+	})
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	var role *iam.Role
+	for i, r := range *roles {
+		if r.Name == name && r.ManagingOrganization == managingOrganization {
+			role = &(*roles)[i]
+			break
+		}
+	}
+	if role == nil {
+		return diag.FromErr(fmt.Errorf("role %q in organization %s: %w", name, managingOrganization, ErrResourceNotFound))
+	}
+	permissions, resp, err := client.Roles.GetRolePermissions(*role)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+
+	d.SetId(role.ID)
+	_ = d.Set("description", role.Description)
+	_ = d.Set("permissions", permissions)
+	return diags
+}
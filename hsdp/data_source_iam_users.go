@@ -0,0 +1,127 @@
+package hsdp
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+const usersPageSize = 100
+
+// userStatuses are the account statuses users can be filtered on
+var userStatuses = []string{"active", "disabled", "locked", "email_verified", "activation_pending"}
+
+func dataSourceIAMUsers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIAMUsersRead,
+		Schema: map[string]*schema.Schema{
+			"organization_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"group_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"login_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"account_status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(userStatuses, false),
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+
+}
+
+// listUsers pages through the users matching opts
+func listUsers(client *iam.Client, opts iam.GetUserOptions) ([]string, *iam.Response, error) {
+	pageSize := strconv.Itoa(usersPageSize)
+	opts.PageSize = &pageSize
+	var users []string
+	for page := 1; ; page++ {
+		pageNumber := strconv.Itoa(page)
+		opts.PageNumber = &pageNumber
+		list, resp, err := client.Users.GetUsers(&opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		users = append(users, list.UserUUIDs...)
+		if !list.HasNextPage || len(list.UserUUIDs) == 0 {
+			return users, resp, nil
+		}
+	}
+}
+
+// userHasStatus reports whether the account of user is in status
+func userHasStatus(user *iamUser, status string) bool {
+	flags := accountStatus(user)[0].(map[string]interface{})
+	if status == "active" {
+		return !flags["disabled"].(bool) && !flags["locked"].(bool)
+	}
+	return flags[status].(bool)
+}
+
+func dataSourceIAMUsersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	organizationID := d.Get("organization_id").(string)
+	loginPrefix := d.Get("login_prefix").(string)
+	status := d.Get("account_status").(string)
+
+	opts := iam.GetUserOptions{OrganizationID: &organizationID}
+	if groupID := d.Get("group_id").(string); groupID != "" {
+		opts.GroupID = &groupID
+	}
+	uuids, resp, err := listUsers(client, opts)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+
+	// Profiles take a lookup per user, so they are only fetched to filter on
+	if loginPrefix == "" && status == "" {
+		d.SetId(organizationID)
+		_ = d.Set("ids", uuids)
+		return diags
+	}
+	ids := make([]string, 0, len(uuids))
+	for _, uuid := range uuids {
+		user, resp, err := config.getUser(ctx, client, uuid)
+		if err != nil {
+			return diagFromResponse(err, resp)
+		}
+		if user == nil {
+			continue
+		}
+		if !strings.HasPrefix(user.LoginID, loginPrefix) {
+			continue
+		}
+		if status != "" && !userHasStatus(user, status) {
+			continue
+		}
+		ids = append(ids, user.ID)
+	}
+
+	d.SetId(organizationID)
+	_ = d.Set("ids", ids)
+	return diags
+}
@@ -0,0 +1,73 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testSeedIAMUsers(f *fakeHSDP) {
+	for _, user := range []fakeObject{
+		{"loginId": "april@example.com", "accountStatus": fakeObject{"disabled": false, "emailVerified": true}},
+		{"loginId": "andy@example.com", "accountStatus": fakeObject{"disabled": true, "emailVerified": false}},
+		{"loginId": "ron@example.com", "accountStatus": fakeObject{"disabled": false, "emailVerified": true}},
+	} {
+		user["managingOrganization"] = "fake-root-org"
+		f.put("User", user)
+	}
+}
+
+// testCheckFakeRequested verifies the number of requests matching method and path prefix
+func testCheckFakeRequested(f *fakeHSDP, method, prefix string, count int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if got := f.requested(method, prefix); got != count {
+			return fmt.Errorf("expected %d %s %s requests, got %d", count, method, prefix, got)
+		}
+		return nil
+	}
+}
+
+func TestDataSourceIAMUsers(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedIAMUsers(f)
+	all := `
+data "hsdp_iam_users" "all" {
+  organization_id = "fake-root-org"
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				// Without a profile filter the users are not looked up one by one
+				Config: f.providerConfig() + all,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_iam_users.all", "ids.#", "3"),
+					testCheckFakeRequested(f, http.MethodGet, "/authorize/identity/User", 0),
+				),
+			},
+			{
+				Config: f.providerConfig() + all + `
+data "hsdp_iam_users" "pending" {
+  organization_id = "fake-root-org"
+  login_prefix    = "a"
+  account_status  = "activation_pending"
+}
+
+data "hsdp_iam_users" "andy" {
+  organization_id = "fake-root-org"
+  login_prefix    = "andy@"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_iam_users.pending", "ids.#", "1"),
+					resource.TestCheckResourceAttrPair("data.hsdp_iam_users.pending", "ids.0", "data.hsdp_iam_users.andy", "ids.0"),
+				),
+			},
+		},
+	})
+}
@@ -2,6 +2,9 @@ package hsdp
 
 import (
 	"context"
+	"sort"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -26,23 +29,60 @@ func dataSourceIAMPermissionsRead(ctx context.Context, d *schema.ResourceData, m
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	resp, _, err := client.Permissions.GetPermissions(nil) // Get all permissions
-
+	permissions, err := config.permissionCatalogue()
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	permissions := make([]string, 0)
-
-	for _, p := range *resp {
-		permissions = append(permissions, p.Name)
-	}
 	d.SetId("permissions")
 	_ = d.Set("permissions", permissions)
 
 	return diags
 }
+
+// permissionCatalogue returns the names of all IAM permissions. The catalogue
+// is fetched once per provider run
+func (c *Config) permissionCatalogue() ([]string, error) {
+	c.permissionsOnce.Do(func() {
+		client, err := c.IAMClient()
+		if err != nil {
+			c.permissionsErr = err
+			return
+		}
+		permissions, _, err := client.Permissions.GetPermissions(nil) // Get all permissions
+		if err != nil {
+			c.permissionsErr = err
+			return
+		}
+		c.permissions = make([]string, 0, len(*permissions))
+		for _, p := range *permissions {
+			c.permissions = append(c.permissions, p.Name)
+		}
+	})
+	return c.permissions, c.permissionsErr
+}
+
+// suggestPermissions returns up to three known permissions nearest to name
+func suggestPermissions(name string, catalogue []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	maxDistance := len(name)/4 + 1
+	var candidates []candidate
+	for _, p := range catalogue {
+		if distance := levenshtein(strings.ToUpper(name), p); distance <= maxDistance {
+			candidates = append(candidates, candidate{p, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < 3; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
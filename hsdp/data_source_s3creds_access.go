@@ -61,11 +61,11 @@ func dataSourceS3CredsAccessRead(_ context.Context, d *schema.ResourceData, meta
 	if client == nil {
 		return diag.FromErr(ErrMissingClientPassword)
 	}
-	s3creds, _, err := client.Access.GetAccess(&creds.GetAccessOptions{
+	s3creds, resp, err := client.Access.GetAccess(&creds.GetAccessOptions{
 		ProductKey: &productKey,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	jsonBytes, err := json.Marshal(&s3creds)
 	if err != nil {
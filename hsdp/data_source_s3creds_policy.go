@@ -108,14 +108,14 @@ func dataSourceS3CredsPolicyRead(_ context.Context, d *schema.ResourceData, meta
 		}
 	}
 
-	credentials, _, err := client.Policy.GetPolicy(&creds.GetPolicyOptions{
+	credentials, resp, err := client.Policy.GetPolicy(&creds.GetPolicyOptions{
 		ProductKey:  &productKey,
 		ManagingOrg: managingOrgPtr,
 		GroupName:   groupNamePtr,
 		ID:          idPtr,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	jsonBytes, err := json.Marshal(&credentials)
 	if err != nil {
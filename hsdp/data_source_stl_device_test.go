@@ -0,0 +1,71 @@
+package hsdp
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testSeedSTLDevice registers device SN-0001 with STL and returns its ID
+func testSeedSTLDevice(f *fakeHSDP) string {
+	return f.putNumbered("STLDevice", fakeObject{
+		"name":         "edge-1",
+		"state":        "ONLINE",
+		"region":       "us-east",
+		"serialNumber": "SN-0001",
+		"primaryInterface": fakeObject{
+			"name":    "eth0",
+			"address": "192.168.1.10",
+		},
+	})
+}
+
+// testCheckSTLSyncs checks how often the config of a device was synced
+func testCheckSTLSyncs(f *fakeHSDP, deviceID string, syncs int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		device := f.get("STLDevice", deviceID)
+		if device == nil {
+			return fmt.Errorf("device %s not found", deviceID)
+		}
+		if got, _ := f.fakeCopy(device)["syncs"].(int); got != syncs {
+			return fmt.Errorf("expected %d syncs of the device config, got %d", syncs, got)
+		}
+		return nil
+	}
+}
+
+func TestDataSourceSTLDevice(t *testing.T) {
+	f := newFakeHSDP(t)
+	id := testSeedSTLDevice(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.providerConfig() + `
+data "hsdp_stl_device" "test" {
+  serial_number = "SN-0001"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.hsdp_stl_device.test", "id", id),
+					resource.TestCheckResourceAttr("data.hsdp_stl_device.test", "name", "edge-1"),
+					resource.TestCheckResourceAttr("data.hsdp_stl_device.test", "state", "ONLINE"),
+					resource.TestCheckResourceAttr("data.hsdp_stl_device.test", "region", "us-east"),
+					resource.TestCheckResourceAttr("data.hsdp_stl_device.test", "primary_interface_ip", "192.168.1.10"),
+				),
+			},
+			{
+				Config: f.providerConfig() + `
+data "hsdp_stl_device" "test" {
+  serial_number = "SN-9999"
+}
+`,
+				ExpectError: regexp.MustCompile(`device not found`),
+			},
+		},
+	})
+}
@@ -0,0 +1,168 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"sync"
+	"time"
+)
+
+// debugLogEntry is a single line in the debug log
+type debugLogEntry struct {
+	Time         string `json:"time"`
+	Type         string `json:"type"`
+	Service      string `json:"service,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Method       string `json:"method,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Status       int    `json:"status,omitempty"`
+	DurationMS   int64  `json:"duration_ms,omitempty"`
+	Request      string `json:"request,omitempty"`
+	Response     string `json:"response,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// debugLogger writes redacted debug entries as JSON lines and rotates the
+// file once it grows beyond maxSize bytes, keeping maxBackups old files
+type debugLogger struct {
+	sync.Mutex
+	filename   string
+	file       *os.File
+	size       int64
+	maxSize    int64
+	maxBackups int
+}
+
+func newDebugLogger(filename string, maxSize int64, maxBackups int) (*debugLogger, error) {
+	l := &debugLogger{
+		filename:   filename,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *debugLogger) open() error {
+	file, err := os.OpenFile(l.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts debug.log.1 to debug.log.2 etc. and moves the current file to debug.log.1
+func (l *debugLogger) rotate() error {
+	_ = l.file.Close()
+	for i := l.maxBackups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.filename, i), fmt.Sprintf("%s.%d", l.filename, i+1))
+	}
+	if l.maxBackups > 0 {
+		_ = os.Rename(l.filename, l.filename+".1")
+	} else {
+		_ = os.Remove(l.filename)
+	}
+	return l.open()
+}
+
+// Log redacts and writes entry
+func (l *debugLogger) Log(entry debugLogEntry) {
+	if l == nil {
+		return
+	}
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	entry.Message = redact(entry.Message)
+	entry.URL = redact(entry.URL)
+	entry.Request = redact(entry.Request)
+	entry.Response = redact(entry.Response)
+	entry.Error = redact(entry.Error)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	l.Lock()
+	defer l.Unlock()
+	if l.file == nil {
+		return
+	}
+	if l.maxSize > 0 && l.size+int64(len(data)) > l.maxSize && l.size > 0 {
+		if err := l.rotate(); err != nil {
+			l.file = nil
+			return
+		}
+	}
+	n, _ := l.file.Write(data)
+	l.size += int64(n)
+}
+
+type resourceContextKey struct{}
+
+type resourceContext struct {
+	resourceType string
+	id           string
+}
+
+// withResource tags the API requests made with ctx in the debug log with the
+// Terraform resource type and ID they are made for. Only requests which carry
+// ctx are tagged, i.e. those of iamRequest. go-hsdp-api methods do not take a
+// context and all its clients send through the shared IAM client, so their
+// requests cannot be attributed to a resource and stay untagged
+func withResource(ctx context.Context, resourceType, id string) context.Context {
+	return context.WithValue(ctx, resourceContextKey{}, resourceContext{resourceType: resourceType, id: id})
+}
+
+// resourceFromContext returns the resource type and ID set by withResource
+func resourceFromContext(ctx context.Context) (string, string) {
+	rc, _ := ctx.Value(resourceContextKey{}).(resourceContext)
+	return rc.resourceType, rc.id
+}
+
+// loggingTransport records every API request and response in the debug log
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *debugLogger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := debugLogEntry{
+		Type:    "http",
+		Service: serviceName(req.URL.Host),
+		Method:  req.Method,
+		URL:     req.URL.String(),
+	}
+	entry.ResourceType, entry.ResourceID = resourceFromContext(req.Context())
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		entry.Request = string(dump)
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	entry.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		if dump, err := httputil.DumpResponse(resp, true); err == nil {
+			entry.Response = string(dump)
+		}
+	}
+	t.logger.Log(entry)
+	return resp, err
+}
@@ -0,0 +1,47 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingTransportResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "debug.log")
+	logger, err := newDebugLogger(filename, 0, 0)
+	if !assert.Nil(t, err) {
+		return
+	}
+	client := &http.Client{Transport: &loggingTransport{next: http.DefaultTransport, logger: logger}}
+
+	ctx := withResource(context.Background(), "hsdp_iam_user", "user-1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+
+	data, err := os.ReadFile(filename)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var entry debugLogEntry
+	assert.Nil(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), &entry))
+	assert.Equal(t, "http", entry.Type)
+	assert.Equal(t, "hsdp_iam_user", entry.ResourceType)
+	assert.Equal(t, "user-1", entry.ResourceID)
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+}
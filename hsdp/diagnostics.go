@@ -0,0 +1,251 @@
+package hsdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/philips-software/go-hsdp-api/cartel"
+	"github.com/philips-software/go-hsdp-api/cdr"
+	"github.com/philips-software/go-hsdp-api/console"
+	"github.com/philips-software/go-hsdp-api/dicom"
+	"github.com/philips-software/go-hsdp-api/iam"
+	"github.com/philips-software/go-hsdp-api/pki"
+	"github.com/philips-software/go-hsdp-api/s3creds"
+)
+
+// requestIDHeaders are the response headers HSDP services use to identify a request
+var requestIDHeaders = []string{
+	"HSDP-Request-ID",
+	"X-Request-Id",
+	"transactionId",
+	"X-Transaction-Id",
+	"X-Correlation-Id",
+	"X-Vcap-Request-Id",
+}
+
+// maxCapturedBody limits how much of an error response is kept for diagnostics
+const maxCapturedBody = 64 * 1024
+
+// apiError describes a failed HSDP API request
+type apiError struct {
+	Method    string
+	URL       string
+	Status    int
+	Code      string
+	Message   string
+	RequestID string
+}
+
+func (e apiError) detail() string {
+	var lines []string
+	if e.Method != "" {
+		lines = append(lines, fmt.Sprintf("Request: %s %s", e.Method, e.URL))
+	}
+	if e.Status != 0 {
+		lines = append(lines, fmt.Sprintf("HTTP status: %d %s", e.Status, http.StatusText(e.Status)))
+	}
+	if e.Code != "" {
+		lines = append(lines, "HSDP error code: "+e.Code)
+	}
+	if e.Message != "" {
+		lines = append(lines, "HSDP error message: "+e.Message)
+	}
+	if e.RequestID != "" {
+		lines = append(lines, "Request ID: "+e.RequestID)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// httpResponse returns the HTTP response of a go-hsdp-api response, if any
+func httpResponse(resp interface{}) *http.Response {
+	switch r := resp.(type) {
+	case *http.Response:
+		return r
+	case *iam.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *cartel.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *console.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *cdr.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *dicom.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *pki.Response:
+		if r != nil {
+			return r.Response
+		}
+	case *s3creds.Response:
+		if r != nil {
+			return r.Response
+		}
+	}
+	return nil
+}
+
+// newAPIError collects the details of a failed request from resp
+func newAPIError(resp interface{}) apiError {
+	r := httpResponse(resp)
+	if r == nil {
+		return apiError{}
+	}
+	e := apiError{Status: r.StatusCode}
+	if r.Request != nil {
+		e.Method = r.Request.Method
+		e.URL = redact(r.Request.URL.String())
+	}
+	for _, header := range requestIDHeaders {
+		if id := r.Header.Get(header); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	if body, ok := r.Body.(*capturedBody); ok {
+		e.Code, e.Message = parseErrorBody(body.data)
+	}
+	return e
+}
+
+// parseErrorBody extracts the error code and message from the various error
+// formats used by HSDP services: FHIR OperationOutcome, OAuth2, Cartel and
+// the generic code/message objects
+func parseErrorBody(data []byte) (string, string) {
+	var body struct {
+		Issue []struct {
+			Code    string `json:"code"`
+			Details struct {
+				Text string `json:"text"`
+			} `json:"details"`
+			Diagnostics string `json:"diagnostics"`
+		} `json:"issue"`
+		Error            interface{} `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+		Code             interface{} `json:"code"`
+		ErrorCode        string      `json:"errorCode"`
+		Message          interface{} `json:"message"`
+		ErrorMessage     string      `json:"errorMessage"`
+		Description      string      `json:"description"`
+		Errors           []struct {
+			Code        interface{} `json:"code"`
+			Message     string      `json:"message"`
+			Description string      `json:"description"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", strings.TrimSpace(redact(string(data)))
+	}
+	if len(body.Issue) > 0 {
+		issue := body.Issue[0]
+		message := issue.Details.Text
+		if message == "" {
+			message = issue.Diagnostics
+		}
+		return issue.Code, redact(message)
+	}
+	if len(body.Errors) > 0 {
+		e := body.Errors[0]
+		message := e.Message
+		if message == "" {
+			message = e.Description
+		}
+		return stringValue(e.Code), redact(message)
+	}
+	code := firstNonEmpty(stringValue(body.Code), body.ErrorCode, stringValue(body.Error))
+	message := firstNonEmpty(body.ErrorDescription, body.ErrorMessage, stringValue(body.Message), body.Description)
+	return code, redact(message)
+}
+
+func stringValue(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return fmt.Sprintf("%g", s)
+	default:
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// apiDiagnostic builds a diagnostic for a failed API call. The detail lists the
+// HTTP status, HSDP error and request ID so it can be shared with HSDP support
+func apiDiagnostic(severity diag.Severity, err error, resp interface{}, attribute string) diag.Diagnostic {
+	d := diag.Diagnostic{
+		Severity: severity,
+		Detail:   newAPIError(resp).detail(),
+	}
+	if err != nil {
+		d.Summary = err.Error()
+	}
+	if attribute != "" {
+		d.AttributePath = cty.GetAttrPath(attribute)
+	}
+	return d
+}
+
+// diagFromResponse is like diag.FromErr but includes the details of the failed
+// API request. attribute optionally names the argument the request was made for
+func diagFromResponse(err error, resp interface{}, attribute ...string) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	return diag.Diagnostics{apiDiagnostic(diag.Error, err, resp, strings.Join(attribute, ""))}
+}
+
+// capturedBody keeps a copy of an error response body so it is still
+// available for diagnostics after the API client consumed it
+type capturedBody struct {
+	io.Reader
+	data []byte
+}
+
+func (b *capturedBody) Close() error {
+	return nil
+}
+
+// errorCaptureTransport replaces the body of error responses with a capturedBody
+type errorCaptureTransport struct {
+	next http.RoundTripper
+}
+
+func (t *errorCaptureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest || resp.Body == nil {
+		return resp, err
+	}
+	data, readErr := ioutil.ReadAll(io.LimitReader(resp.Body, maxCapturedBody))
+	_ = resp.Body.Close()
+	if readErr != nil {
+		return resp, readErr
+	}
+	resp.Body = &capturedBody{Reader: bytes.NewReader(data), data: data}
+	return resp, nil
+}
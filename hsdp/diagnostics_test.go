@@ -0,0 +1,59 @@
+package hsdp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	code, message := parseErrorBody([]byte(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"invalid","details":{"text":"Group name already exists"}}]}`))
+	assert.Equal(t, "invalid", code)
+	assert.Equal(t, "Group name already exists", message)
+
+	code, message = parseErrorBody([]byte(`{"error":"invalid_client","error_description":"Client authentication failed"}`))
+	assert.Equal(t, "invalid_client", code)
+	assert.Equal(t, "Client authentication failed", message)
+
+	code, message = parseErrorBody([]byte(`{"errors":[{"code":"1000","message":"Quota exceeded"}]}`))
+	assert.Equal(t, "1000", code)
+	assert.Equal(t, "Quota exceeded", message)
+
+	code, message = parseErrorBody([]byte(`Bad Gateway`))
+	assert.Equal(t, "", code)
+	assert.Equal(t, "Bad Gateway", message)
+}
+
+func TestDiagFromResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("HSDP-Request-ID", "req-1234")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"resourceType":"OperationOutcome","issue":[{"code":"duplicate","details":{"text":"Role exists"}}]}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &errorCaptureTransport{next: http.DefaultTransport}}
+	resp, err := client.Post(server.URL+"/authorize/identity/Role", "application/json", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	diags := diagFromResponse(errors.New("create role failed"), resp, "name")
+	if !assert.Len(t, diags, 1) {
+		return
+	}
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "create role failed", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "HTTP status: 409 Conflict")
+	assert.Contains(t, diags[0].Detail, "HSDP error code: duplicate")
+	assert.Contains(t, diags[0].Detail, "HSDP error message: Role exists")
+	assert.Contains(t, diags[0].Detail, "Request ID: req-1234")
+	assert.Equal(t, 1, len(diags[0].AttributePath))
+
+	assert.Nil(t, diagFromResponse(nil, resp))
+}
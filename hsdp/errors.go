@@ -13,11 +13,22 @@ var (
 	ErrMissingClientPassword    = errors.New("missing client password")
 	ErrInvalidResponse          = errors.New("invalid response received")
 	ErrResourceNotFound         = errors.New("resource not found")
-	ErrIntermittent             = errors.New("intermittent error detected")
 	ErrDeleteGroupFailed        = errors.New("delete group failed")
+	ErrDeleteRoleFailed         = errors.New("delete role failed")
 	ErrDeleteMFAPolicyFailed    = errors.New("delete of MFA policy failed")
 	ErrDeleteClientFailed       = errors.New("delete client failed")
 	ErrDeleteServiceFailed      = errors.New("delete service failed")
 	ErrDeleteSubscriptionFailed = errors.New("delete subscription failed")
+	ErrCreateApplicationFailed  = errors.New("create application failed")
 	ErrMissingOrganizationID    = errors.New("missing organization ID")
+	ErrMissingProfile           = errors.New("profile not found")
+	ErrMissingCredentialsFile   = errors.New("credentials file not found")
+	ErrTokenRenewFailed         = errors.New("token renewal failed")
+	ErrInvalidCABundle          = errors.New("no certificates found in CA bundle")
+	ErrMissingToken             = errors.New("no IAM token available")
+	ErrInvalidGroupMembershipID = errors.New("invalid group membership ID")
+	ErrInvalidEmailTemplateID   = errors.New("invalid email template ID")
+	ErrPolicyAlreadyExists      = errors.New("policy already exists")
+	ErrUserAlreadyExists        = errors.New("user already exists")
+	ErrInvalidImportID          = errors.New("invalid import ID")
 )
@@ -0,0 +1,966 @@
+package hsdp
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeHSDP is an in-process stand-in for the HSDP APIs used by the resources.
+// IAM/IDM resources are kept in a generic in-memory store and Cartel gets a
+// small dedicated handler, the other services are in fake_services_test.go.
+// Container host SSH connections reach it through the provider http_proxy,
+// see fake_ssh_test.go. Faults can be injected to exercise drift and error
+// recovery
+type fakeHSDP struct {
+	*httptest.Server
+	t *testing.T
+
+	sync.Mutex
+	nextID    int
+	tokens    int                              // access tokens issued
+	objects   map[string]map[string]fakeObject // type -> id -> object
+	instances map[string]fakeObject            // Cartel instances by name tag
+	faults    []*fakeFault
+	requests  []string // method and path of every request received
+	omitTotal bool     // leave total out of service searches, as IAM may do
+	pkiCA     *x509.Certificate
+	pkiCAKey  *ecdsa.PrivateKey
+
+	sshConfig     *ssh.ServerConfig
+	sshPrivateKey string
+	sshHosts      map[string]*fakeSSHHost // SSH hosts by address
+}
+
+type fakeObject map[string]interface{}
+
+type fakeFault struct {
+	method string
+	prefix string
+	status int
+	count  int
+	after  bool // perform the request before failing it
+}
+
+// fakePermissions is the permission catalogue of the fake
+var fakePermissions = []string{
+	"GROUP.READ", "GROUP.WRITE",
+	"ROLE.READ", "ROLE.WRITE",
+	"USER.READ", "USER.WRITE",
+	"ORGANIZATION.READ", "ORGANIZATION.WRITE",
+}
+
+func newFakeHSDP(t *testing.T) *fakeHSDP {
+	f := &fakeHSDP{
+		t:         t,
+		objects:   make(map[string]map[string]fakeObject),
+		instances: make(map[string]fakeObject),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize/oauth2/token", f.token)
+	mux.HandleFunc("/authorize/oauth2/introspect", f.introspect)
+	mux.HandleFunc("/authorize/identity/", f.identity)
+	mux.HandleFunc("/authorize/scim/v2/", f.identity)
+	mux.HandleFunc("/security/users", f.users)
+	mux.HandleFunc("/v3/api/", f.cartel)
+	mux.HandleFunc("/oauth/token", f.token)
+	mux.HandleFunc("/core/credentials/Policy", f.s3credsPolicies)
+	mux.HandleFunc("/core/credentials/Policy/", f.s3credsPolicies)
+	mux.HandleFunc("/v3/metrics/", f.autoscalers)
+	mux.HandleFunc("/core/pki/", f.pki)
+	mux.HandleFunc("/store/fhir/", f.fhir)
+	mux.HandleFunc("/dicom-config/store/dicom/", f.dicom)
+	mux.HandleFunc("/stl/graphql", f.stl)
+	mux.HandleFunc("/", f.generic)
+	for _, name := range fakePermissions {
+		f.put("Permission", fakeObject{"id": name, "name": name})
+	}
+	f.Server = httptest.NewServer(f.withProxy(f.withFaults(mux)))
+	t.Cleanup(f.Close)
+	t.Cleanup(f.checkFaults)
+	return f
+}
+
+// checkFaults fails the test when an injected fault was never hit, as the
+// test then did not exercise what it meant to
+func (f *fakeHSDP) checkFaults() {
+	f.Lock()
+	defer f.Unlock()
+	for _, fault := range f.faults {
+		if fault.count > 0 {
+			f.t.Errorf("%d injected %d faults on %s %s were not hit", fault.count, fault.status, fault.method, fault.prefix)
+		}
+	}
+}
+
+// providerConfig returns a provider block pointing every service at the fake,
+// followed by the extra settings, one per line
+func (f *fakeHSDP) providerConfig(extra ...string) string {
+	host := strings.TrimPrefix(f.URL, "http://")
+	credentials := filepath.Join(f.t.TempDir(), "credentials")
+	settings := ""
+	for _, setting := range extra {
+		settings += "  " + setting + "\n"
+	}
+	return fmt.Sprintf(`
+provider "hsdp" {
+  credentials_file   = %q
+  iam_url            = %q
+  idm_url            = %q
+  s3creds_url        = %q
+  uaa_url            = %q
+  uaa_username       = "uaa-user"
+  uaa_password       = "uaa-password"
+  console_url        = %q
+  pki_url            = %q
+  stl_url            = "%s/stl/graphql"
+  oauth2_client_id   = "client"
+  oauth2_password    = "secret"
+  org_admin_username = "admin"
+  org_admin_password = "password"
+  cartel_host        = %q
+  cartel_token       = "token"
+  cartel_secret      = "secret"
+  cartel_no_tls      = true
+  retry_max          = 3
+  retry_wait_min     = 1
+  retry_wait_max     = 1
+%s}
+`, credentials, f.URL, f.URL, f.URL, f.URL, f.URL, f.URL, f.URL, host, settings)
+}
+
+// config returns a provider configuration pointing every service at the fake,
+// for tests which call resource functions directly
+func (f *fakeHSDP) config() *Config {
+	return testConfigure(f.t, map[string]interface{}{
+		"credentials_file":   filepath.Join(f.t.TempDir(), "credentials"),
+		"iam_url":            f.URL,
+		"idm_url":            f.URL,
+		"oauth2_client_id":   "client",
+		"oauth2_password":    "secret",
+		"org_admin_username": "admin",
+		"org_admin_password": "password",
+		"retry_max":          0,
+	})
+}
+
+// providerFactories returns a fresh provider for each test step
+func (f *fakeHSDP) providerFactories() map[string]func() (*schema.Provider, error) {
+	// Keep HSDP_* settings of the developer from leaking into the tests
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "HSDP_") {
+			continue
+		}
+		kv := strings.SplitN(env, "=", 2)
+		f.t.Setenv(kv[0], "")
+	}
+	return map[string]func() (*schema.Provider, error){
+		"hsdp": func() (*schema.Provider, error) {
+			return Provider("v0.0.0"), nil
+		},
+	}
+}
+
+// failNext makes the next count requests matching method and path prefix fail with status
+func (f *fakeHSDP) failNext(method, prefix string, status, count int) {
+	f.Lock()
+	defer f.Unlock()
+	f.faults = append(f.faults, &fakeFault{method: method, prefix: prefix, status: status, count: count})
+}
+
+// failAfterNext is like failNext but carries out the requests before failing
+// them, e.g. to simulate a gateway timeout on a create that did succeed
+func (f *fakeHSDP) failAfterNext(method, prefix string, status, count int) {
+	f.Lock()
+	defer f.Unlock()
+	f.faults = append(f.faults, &fakeFault{method: method, prefix: prefix, status: status, count: count, after: true})
+}
+
+func (f *fakeHSDP) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		for _, fault := range f.faults {
+			if fault.count > 0 && fault.method == r.Method && strings.HasPrefix(r.URL.Path, fault.prefix) {
+				fault.count--
+				f.Unlock()
+				if fault.after {
+					next.ServeHTTP(httptest.NewRecorder(), r)
+				}
+				f.writeOutcome(w, fault.status, "exception", "injected fault")
+				return
+			}
+		}
+		f.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requested returns the number of requests received matching method and path prefix
+func (f *fakeHSDP) requested(method, prefix string) int {
+	f.Lock()
+	defer f.Unlock()
+	n := 0
+	for _, request := range f.requests {
+		if strings.HasPrefix(request, method+" "+prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// put stores obj under type and returns its ID
+func (f *fakeHSDP) put(resourceType string, obj fakeObject) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.putLocked(resourceType, obj)
+}
+
+func (f *fakeHSDP) putLocked(resourceType string, obj fakeObject) string {
+	id, _ := obj["id"].(string)
+	if id == "" {
+		f.nextID++
+		id = fmt.Sprintf("%08d-0000-4000-8000-%012d", f.nextID, f.nextID)
+		obj["id"] = id
+	}
+	if _, ok := obj["meta"]; !ok {
+		obj["meta"] = fakeObject{"version": "W/\"1\""}
+	}
+	if f.objects[resourceType] == nil {
+		f.objects[resourceType] = make(map[string]fakeObject)
+	}
+	f.objects[resourceType][id] = obj
+	return id
+}
+
+func (f *fakeHSDP) get(resourceType, id string) fakeObject {
+	f.Lock()
+	defer f.Unlock()
+	return f.objects[resourceType][id]
+}
+
+// remove deletes an object behind Terraform's back to simulate drift
+func (f *fakeHSDP) remove(resourceType, id string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.objects[resourceType], id)
+}
+
+// find returns the ID of the first object of type with field set to value
+func (f *fakeHSDP) find(resourceType, field, value string) string {
+	f.Lock()
+	defer f.Unlock()
+	for id, obj := range f.objects[resourceType] {
+		if fmt.Sprint(obj[field]) == value {
+			return id
+		}
+	}
+	return ""
+}
+
+func (f *fakeHSDP) count(resourceType string) int {
+	f.Lock()
+	defer f.Unlock()
+	return len(f.objects[resourceType])
+}
+
+func (f *fakeHSDP) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("HSDP-Request-ID", fmt.Sprintf("fake-%d", status))
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func (f *fakeHSDP) readJSON(r *http.Request) fakeObject {
+	obj := fakeObject{}
+	data, _ := ioutil.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &obj)
+	return obj
+}
+
+func (f *fakeHSDP) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	if r.Form.Get("grant_type") == "password" && r.Form.Get("password") == "wrong" {
+		f.writeJSON(w, http.StatusUnauthorized, fakeObject{"error": "invalid_grant"})
+		return
+	}
+	f.Lock()
+	f.tokens++
+	token := fmt.Sprintf("fake-access-token-%d", f.tokens)
+	f.Unlock()
+	response := fakeObject{
+		"access_token":  token,
+		"refresh_token": "fake-refresh-token",
+		"id_token":      "fake-id-token",
+		"token_type":    "Bearer",
+		"expires_in":    1799,
+	}
+	// Service identities only get an access token
+	if r.Form.Get("grant_type") == "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		delete(response, "refresh_token")
+		delete(response, "id_token")
+	}
+	f.writeJSON(w, http.StatusOK, response)
+}
+
+// introspect describes the provider session unless a service or expired token is posted
+func (f *fakeHSDP) introspect(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	switch r.Form.Get("token") {
+	case "fake-expired-token":
+		f.writeJSON(w, http.StatusOK, fakeObject{"active": false})
+		return
+	case "fake-service-token":
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"active":        true,
+			"scope":         "openid tdr.contract",
+			"sub":           "ci@deploy.fake-application.example.com",
+			"client_id":     "ci-client",
+			"token_type":    "Bearer",
+			"identity_type": "Service",
+			"exp":           4102444800,
+			"organizations": fakeObject{
+				"managingOrganization": "fake-root-org",
+				"organizationList": []fakeObject{{
+					"organizationId":   "fake-root-org",
+					"organizationName": "Root",
+					"permissions":      []string{"ROLE.READ"},
+				}, {
+					"organizationId":   "fake-child-org",
+					"organizationName": "Child",
+					"permissions":      []string{"GROUP.READ", "GROUP.WRITE"},
+				}},
+			},
+		})
+		return
+	}
+	f.writeJSON(w, http.StatusOK, fakeObject{
+		"active":        true,
+		"scope":         "auth_iam_organization auth_iam_introspect",
+		"username":      "admin",
+		"client_id":     "client",
+		"identity_type": "user",
+		"exp":           4102444800,
+		"organizations": fakeObject{
+			"managingOrganization": "fake-root-org",
+			"organizationList": []fakeObject{{
+				"organizationId": "fake-root-org",
+				"permissions":    []string{"GROUP.READ", "GROUP.WRITE", "ROLE.READ", "ROLE.WRITE"},
+			}},
+		},
+	})
+}
+
+// setMembers replaces the users of a group as if they were changed in the IAM console
+func (f *fakeHSDP) setMembers(groupID string, users ...string) {
+	f.Lock()
+	defer f.Unlock()
+	if group := f.objects["Group"][groupID]; group != nil {
+		group["_users"] = users
+	}
+}
+
+// identity implements the IAM identity and SCIM APIs as a generic store:
+// POST /{Type} creates, GET|PUT|DELETE /{Type}/{id} reads, updates and deletes,
+// GET /{Type}?field=value searches and POST /{Type}/{id}/$operation manages
+// role, permission, member and service assignments
+func (f *fakeHSDP) identity(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer fake-access-token-") {
+		f.writeOutcome(w, http.StatusUnauthorized, "security", "missing or invalid token")
+		return
+	}
+	if version := fakeAPIVersion(r); version != "" && r.Header.Get("Api-Version") != version {
+		f.writeOutcome(w, http.StatusBadRequest, "not-supported", "unsupported API version")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/authorize/identity/")
+	path = strings.TrimPrefix(path, "/authorize/scim/v2/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	resourceType := parts[0]
+	if t, ok := scimTypes[resourceType]; ok {
+		resourceType = t
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		obj := f.readJSON(r)
+		f.Lock()
+		for _, existing := range f.objects[resourceType] {
+			if name, ok := obj["name"].(string); ok && existing["name"] == name &&
+				fakeScope(existing) == fakeScope(obj) {
+				f.Unlock()
+				f.writeJSON(w, http.StatusConflict, fakeObject{"issue": []fakeObject{{"code": "conflict"}}})
+				return
+			}
+		}
+		switch resourceType {
+		case "User":
+			fakeProfile(obj)
+		case "Client":
+			// IAM reports the realms of a client, which updates must send back
+			obj["realms"] = []string{"fake-realm"}
+		case "Service":
+			f.fakeServiceIdentity(obj)
+		}
+		id := f.putLocked(resourceType, obj)
+		f.Unlock()
+		w.Header().Set("Location", r.URL.Path+"/"+id)
+		f.writeJSON(w, http.StatusCreated, obj)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		f.search(w, resourceType, r.URL.Query())
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		if obj := f.get(resourceType, parts[1]); obj != nil {
+			f.writeJSON(w, http.StatusOK, obj)
+			return
+		}
+		f.writeJSON(w, http.StatusNotFound, fakeObject{"issue": []fakeObject{{"code": "not-found"}}})
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		if f.get(resourceType, parts[1]) == nil {
+			f.writeJSON(w, http.StatusNotFound, nil)
+			return
+		}
+		obj := f.readJSON(r)
+		obj["id"] = parts[1]
+		f.Lock()
+		for k, v := range f.objects[resourceType][parts[1]] {
+			if _, ok := obj[k]; !ok && (partialUpdates[resourceType] || strings.HasPrefix(k, "_") || k == "accountStatus") {
+				obj[k] = v
+			}
+		}
+		if resourceType == "User" {
+			fakeProfile(obj)
+		}
+		f.putLocked(resourceType, obj)
+		f.Unlock()
+		f.writeJSON(w, http.StatusOK, obj)
+	case len(parts) == 2 && r.Method == http.MethodPatch:
+		f.patch(w, r, resourceType, parts[1])
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if f.get(resourceType, parts[1]) == nil {
+			f.writeJSON(w, http.StatusNotFound, nil)
+			return
+		}
+		f.remove(resourceType, parts[1])
+		// Organizations are deleted asynchronously
+		if resourceType == "Organizations" {
+			f.writeJSON(w, http.StatusAccepted, nil)
+			return
+		}
+		f.writeJSON(w, http.StatusNoContent, nil)
+	case len(parts) == 3 && strings.HasPrefix(parts[2], "$"):
+		f.operation(w, r, resourceType, parts[1], parts[2])
+	default:
+		f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// fakeAPIVersions are the API versions IAM requires by method and path
+// prefix, requests with another Api-Version header are rejected. An empty
+// method matches all methods
+var fakeAPIVersions = []struct {
+	method  string
+	prefix  string
+	version string
+}{
+	{http.MethodGet, "/authorize/identity/User", "2"},
+	{http.MethodPut, "/authorize/identity/User/", "2"},
+	{"", "/authorize/scim/v2/Organizations", "2"},
+	{"", "/authorize/scim/v2/MFAPolicies", "2"},
+	{"", "/authorize/scim/v2/Users", "2"},
+}
+
+// fakeAPIVersion returns the API version r must be sent with, if any
+func fakeAPIVersion(r *http.Request) string {
+	for _, v := range fakeAPIVersions {
+		if (v.method == "" || v.method == r.Method) && strings.HasPrefix(r.URL.Path, v.prefix) {
+			return v.version
+		}
+	}
+	return ""
+}
+
+// fakeServiceIdentity fills in what IAM generates for a new service: its
+// service ID, the organization of its proposition and the key expiry
+func (f *fakeHSDP) fakeServiceIdentity(obj fakeObject) {
+	application := fmt.Sprint(obj["applicationId"])
+	obj["serviceId"] = fmt.Sprintf("%s@%s.fake.example.com", strings.ToLower(fmt.Sprint(obj["name"])), application)
+	if app := f.objects["Application"][application]; app != nil {
+		if prop := f.objects["Proposition"][fmt.Sprint(app["propositionId"])]; prop != nil {
+			obj["organizationId"] = prop["organizationId"]
+		}
+	}
+	validity, _ := obj["validity"].(float64)
+	obj["expiresOn"] = time.Now().AddDate(0, int(validity), 0).UTC().Format(time.RFC3339)
+}
+
+// partialUpdates are the types whose PUT only carries the fields which can change
+var partialUpdates = map[string]bool{"Group": true}
+
+// fakeScope is what names are unique within: the organization, or the
+// proposition of an application and the application of a service
+func fakeScope(obj fakeObject) string {
+	return fmt.Sprint(fakeOrganization(obj), "/", obj["propositionId"], "/", obj["applicationId"])
+}
+
+// fakeOrganization returns the organization of obj. Propositions keep it in
+// organizationId, the other types in managingOrganization
+func fakeOrganization(obj fakeObject) interface{} {
+	if org, ok := obj["organizationId"]; ok {
+		return org
+	}
+	return obj["managingOrganization"]
+}
+
+// scimTypes maps SCIM resource types to the identity types they are stored as
+var scimTypes = map[string]string{"Users": "User"}
+
+// fakeProfile adds the profile fields IAM returns for a user. New users
+// await activation
+func fakeProfile(person fakeObject) {
+	telecom, _ := person["telecom"].([]interface{})
+	for _, t := range telecom {
+		if entry, ok := t.(map[string]interface{}); ok && entry["system"] == "email" {
+			person["emailAddress"] = entry["value"]
+		}
+	}
+	if _, ok := person["accountStatus"]; !ok {
+		person["accountStatus"] = fakeObject{"disabled": true, "emailVerified": false}
+	}
+}
+
+// patch applies SCIM PatchOp replace operations. Replacing active of a user
+// enables or disables its account
+func (f *fakeHSDP) patch(w http.ResponseWriter, r *http.Request, resourceType, id string) {
+	body := f.readJSON(r)
+	f.Lock()
+	defer f.Unlock()
+	obj := f.objects[resourceType][id]
+	if obj == nil {
+		f.writeJSON(w, http.StatusNotFound, nil)
+		return
+	}
+	operations, _ := body["Operations"].([]interface{})
+	for _, o := range operations {
+		op, _ := o.(map[string]interface{})
+		path, _ := op["path"].(string)
+		if op["op"] != "replace" || path == "" {
+			continue
+		}
+		obj[path] = op["value"]
+		if active, ok := op["value"].(bool); ok && path == "active" {
+			obj["accountStatus"] = fakeObject{"disabled": !active, "emailVerified": true}
+		}
+	}
+	f.writeJSON(w, http.StatusOK, obj)
+}
+
+// operationFields maps $operations to the hidden assignment list they manage
+var operationFields = map[string]struct {
+	field string
+	add   bool
+}{
+	"$assign-role":       {"_roles", true},
+	"$remove-role":       {"_roles", false},
+	"$assign-permission": {"_permissions", true},
+	"$remove-permission": {"_permissions", false},
+	"$add-members":       {"_users", true},
+	"$remove-members":    {"_users", false},
+	"$assign":            {"_services", true},
+	"$remove":            {"_services", false},
+}
+
+func (f *fakeHSDP) operation(w http.ResponseWriter, r *http.Request, resourceType, id, op string) {
+	f.Lock()
+	defer f.Unlock()
+	obj := f.objects[resourceType][id]
+	if obj == nil {
+		f.writeJSON(w, http.StatusNotFound, nil)
+		return
+	}
+	if op == "$scopes" {
+		// Services add or remove scopes, clients replace them
+		body := f.readJSON(r)
+		for _, field := range []string{"scopes", "defaultScopes"} {
+			values := expandStringList(toInterfaceList(body[field]))
+			current, _ := obj[field].([]interface{})
+			list := expandStringList(current)
+			switch body["action"] {
+			case "add":
+				list = append(list, difference(values, list)...)
+			case "remove":
+				list = difference(list, values)
+			default:
+				list = values
+			}
+			obj[field] = toInterfaceList(list)
+		}
+		f.writeJSON(w, http.StatusNoContent, nil)
+		return
+	}
+	spec, ok := operationFields[op]
+	if !ok {
+		f.writeJSON(w, http.StatusOK, fakeObject{})
+		return
+	}
+	data, _ := ioutil.ReadAll(r.Body)
+	var body interface{}
+	_ = json.Unmarshal(data, &body)
+	values := collectValues(body)
+
+	current, _ := obj[spec.field].([]string)
+	if spec.add {
+		current = append(current, difference(values, current)...)
+	} else {
+		current = difference(current, values)
+	}
+	obj[spec.field] = current
+	f.writeJSON(w, http.StatusOK, fakeObject{})
+}
+
+// toInterfaceList converts a decoded JSON array or string slice to []interface{}
+func toInterfaceList(v interface{}) []interface{} {
+	switch l := v.(type) {
+	case []interface{}:
+		return l
+	case []string:
+		list := make([]interface{}, len(l))
+		for i, s := range l {
+			list[i] = s
+		}
+		return list
+	}
+	return nil
+}
+
+// collectValues gathers the IDs and names from an $operation payload, e.g.
+// {"roles":["id"]}, {"memberType":"SERVICE","value":["id"]} or a Parameters
+// resource with references
+func collectValues(body interface{}) []string {
+	var values []string
+	switch v := body.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			switch k {
+			case "resourceType", "name", "memberType":
+				continue
+			}
+			values = append(values, collectValues(e)...)
+		}
+	case []interface{}:
+		for _, e := range v {
+			values = append(values, collectValues(e)...)
+		}
+	case string:
+		values = append(values, strings.TrimPrefix(v, "Group/"))
+	}
+	return values
+}
+
+// search answers collection queries. The roleId, groupId and organizationId
+// parameters resolve assignments, other parameters filter on object fields
+func (f *fakeHSDP) search(w http.ResponseWriter, resourceType string, query url.Values) {
+	f.Lock()
+	defer f.Unlock()
+	var results []fakeObject
+	switch {
+	case resourceType == "Permission" && query.Get("roleId") != "":
+		role := f.objects["Role"][query.Get("roleId")]
+		names, _ := role["_permissions"].([]string)
+		for _, name := range names {
+			results = append(results, fakeObject{"id": name, "name": name})
+		}
+	case resourceType == "Role" && query.Get("groupId") != "":
+		group := f.objects["Group"][query.Get("groupId")]
+		ids, _ := group["_roles"].([]string)
+		for _, id := range ids {
+			if role := f.objects["Role"][id]; role != nil {
+				results = append(results, role)
+			}
+		}
+	case resourceType == "Group" && query.Get("memberId") != "":
+		field := "_users"
+		if strings.EqualFold(query.Get("memberType"), "SERVICE") {
+			field = "_services"
+		}
+		for _, group := range f.objects["Group"] {
+			members, _ := group[field].([]string)
+			if len(intersection(members, []string{query.Get("memberId")})) > 0 && fakeMatches(group, query) {
+				results = append(results, group)
+			}
+		}
+	case resourceType == "Service" && query.Get("groupId") != "":
+		group := f.objects["Group"][query.Get("groupId")]
+		ids, _ := group["_services"].([]string)
+		for _, id := range ids {
+			if service := f.objects["Service"][id]; service != nil {
+				results = append(results, service)
+				continue
+			}
+			results = append(results, fakeObject{"id": id})
+		}
+	default:
+		for _, obj := range f.objects[resourceType] {
+			if fakeMatches(obj, query) {
+				results = append(results, obj)
+			}
+		}
+	}
+	total := len(results)
+	count, err := strconv.Atoi(query.Get("_count"))
+	if err != nil {
+		count, err = strconv.Atoi(query.Get("count"))
+	}
+	if err == nil && count > 0 {
+		page, err := strconv.Atoi(query.Get("_page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return fmt.Sprint(results[i]["id"]) < fmt.Sprint(results[j]["id"])
+		})
+		start := (page - 1) * count
+		// SCIM pages by the 1-based index of the first result instead
+		if index, err := strconv.Atoi(query.Get("startIndex")); err == nil && index > 0 {
+			start = index - 1
+		}
+		if start > total {
+			start = total
+		}
+		end := start + count
+		if end > total {
+			end = total
+		}
+		results = results[start:end]
+	}
+	if resourceType == "Group" {
+		results = groupEntries(results)
+	}
+	bundle := fakeObject{
+		"total":        total,
+		"entry":        results,
+		"totalResults": total,
+		"Resources":    results,
+	}
+	if f.omitTotal && resourceType == "Service" {
+		delete(bundle, "total")
+	}
+	f.writeJSON(w, http.StatusOK, bundle)
+}
+
+// groupEntries wraps groups like the IAM group search does, which returns
+// the ID, name, description and organization in the resource of each entry
+func groupEntries(groups []fakeObject) []fakeObject {
+	entries := make([]fakeObject, 0, len(groups))
+	for _, group := range groups {
+		entries = append(entries, fakeObject{"resource": fakeObject{
+			"_id":              group["id"],
+			"groupName":        group["name"],
+			"groupDescription": group["description"],
+			"orgId":            group["managingOrganization"],
+		}})
+	}
+	return entries
+}
+
+// users implements the legacy user search, which looks up users by login or
+// pages through the members of a group or organization
+func (f *fakeHSDP) users(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 100
+	}
+	pageNumber, err := strconv.Atoi(query.Get("pageNumber"))
+	if err != nil || pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	if loginID := query.Get("loginId"); loginID != "" {
+		id := f.find("User", "loginId", loginID)
+		var users []fakeObject
+		if id != "" {
+			users = append(users, fakeObject{"userUUID": id})
+		}
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"exchange":     fakeObject{"users": users},
+			"responseCode": "200",
+		})
+		return
+	}
+
+	f.Lock()
+	group := f.objects["Group"][query.Get("groupId")]
+	members, _ := group["_users"].([]string)
+	if query.Get("groupId") == "" {
+		for id, user := range f.objects["User"] {
+			if user["managingOrganization"] == query.Get("organizationID") {
+				members = append(members, id)
+			}
+		}
+		sort.Strings(members)
+	}
+	f.Unlock()
+
+	var page []fakeObject
+	start := (pageNumber - 1) * pageSize
+	for i := start; i < len(members) && i < start+pageSize; i++ {
+		page = append(page, fakeObject{"userUUID": members[i]})
+	}
+	f.writeJSON(w, http.StatusOK, fakeObject{
+		"exchange": fakeObject{
+			"users":          page,
+			"nextPageExists": start+pageSize < len(members),
+		},
+		"responseCode": "200",
+	})
+}
+
+func fakeMatches(obj fakeObject, query url.Values) bool {
+	fields := map[string]string{
+		"_id":           "id",
+		"name":          "name",
+		"parentId":      "parentId",
+		"propositionId": "propositionId",
+		"applicationId": "applicationId",
+		"type":          "type",
+		"locale":        "locale",
+	}
+	for param, field := range fields {
+		if v := query.Get(param); v != "" && fmt.Sprint(obj[field]) != v {
+			return false
+		}
+	}
+	if v := query.Get("organizationId"); v != "" && fmt.Sprint(fakeOrganization(obj)) != v {
+		return false
+	}
+	// Users are looked up by UUID or login ID
+	if v := query.Get("userId"); v != "" && obj["id"] != v && obj["loginId"] != v {
+		return false
+	}
+	// SCIM searches filter with a single attribute eq "value" expression
+	if filter := strings.SplitN(query.Get("filter"), " eq ", 2); len(filter) == 2 {
+		query = url.Values{filter[0]: {strings.Trim(filter[1], `"`)}}
+	}
+	if v := query.Get("parent.value"); v != "" {
+		var parent interface{}
+		switch p := obj["parent"].(type) {
+		case map[string]interface{}:
+			parent = p["value"]
+		case fakeObject:
+			parent = p["value"]
+		}
+		if fmt.Sprint(parent) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// cartel implements the Cartel operations used by hsdp_container_host, with
+// the response shapes of the Cartel API
+func (f *fakeHSDP) cartel(w http.ResponseWriter, r *http.Request) {
+	op := strings.TrimPrefix(r.URL.Path, "/v3/api/")
+	body := f.readJSON(r)
+	nameTag := ""
+	if tags, ok := body["name-tag"].([]interface{}); ok && len(tags) > 0 {
+		nameTag, _ = tags[0].(string)
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	instance, exists := f.instances[nameTag]
+	switch op {
+	case "create":
+		f.nextID++
+		instance = fakeObject{
+			"instance_id":     fmt.Sprintf("i-%012d", f.nextID),
+			"name_tag":        nameTag,
+			"private_address": fmt.Sprintf("10.0.0.%d", f.nextID),
+			"role":            body["role"],
+			"instance_type":   body["instance_type"],
+			"protection":      body["protect"],
+			"security_groups": append([]interface{}{"base"}, toInterfaceList(body["security_group"])...),
+			"ldap_groups":     append([]interface{}{}, toInterfaceList(body["ldap_groups"])...),
+			"block_devices":   []string{"/dev/xvda"},
+			"tags":            body["tags"],
+		}
+		f.instances[nameTag] = instance
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"message": []fakeObject{{
+				"instance_id": instance["instance_id"],
+				"ip_address":  instance["private_address"],
+				"name":        nameTag,
+				"role":        instance["role"],
+			}},
+			"result": "Success",
+		})
+	case "get_all_instances":
+		list := []fakeObject{}
+		for _, instance := range f.instances {
+			list = append(list, instance)
+		}
+		f.writeJSON(w, http.StatusOK, list)
+	case "deployment_status":
+		if !exists {
+			f.writeJSON(w, http.StatusBadRequest, fakeObject{"message": "Instance not found"})
+			return
+		}
+		f.writeJSON(w, http.StatusOK, fakeObject{nameTag: fakeObject{"deploy_state": "succeeded"}})
+	case "instance_details":
+		if !exists {
+			f.writeJSON(w, http.StatusBadRequest, fakeObject{"message": "Instance not found"})
+			return
+		}
+		f.writeJSON(w, http.StatusOK, []fakeObject{{nameTag: instance}})
+	case "destroy":
+		delete(f.instances, nameTag)
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"AWS":    "Instance terminated.",
+			"Cartel": fakeObject{nameTag: "Instance removed."},
+		})
+	default:
+		f.writeJSON(w, http.StatusOK, fakeObject{"message": "ok", "result": "Success"})
+	}
+}
+
+// generic answers requests of the services which are not modelled with plain
+// store semantics, keyed by the first path segment
+func (f *fakeHSDP) generic(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	resourceType := parts[0]
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 1:
+		obj := f.readJSON(r)
+		f.put(resourceType, obj)
+		f.writeJSON(w, http.StatusCreated, obj)
+	case r.Method == http.MethodGet && len(parts) >= 2:
+		if obj := f.get(resourceType, parts[len(parts)-1]); obj != nil {
+			f.writeJSON(w, http.StatusOK, obj)
+			return
+		}
+		f.writeJSON(w, http.StatusNotFound, nil)
+	case r.Method == http.MethodDelete && len(parts) >= 2:
+		f.remove(resourceType, parts[len(parts)-1])
+		f.writeJSON(w, http.StatusNoContent, nil)
+	default:
+		f.writeJSON(w, http.StatusNotFound, nil)
+	}
+}
@@ -0,0 +1,840 @@
+package hsdp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philips-software/go-hsdp-api/s3creds"
+)
+
+// putNumbered stores obj under type with the next integer ID, as S3 Credentials uses
+func (f *fakeHSDP) putNumbered(resourceType string, obj fakeObject) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.putNumberedLocked(resourceType, obj)
+}
+
+func (f *fakeHSDP) putNumberedLocked(resourceType string, obj fakeObject) string {
+	f.nextID++
+	obj["id"] = f.nextID
+	if f.objects[resourceType] == nil {
+		f.objects[resourceType] = make(map[string]fakeObject)
+	}
+	id := strconv.Itoa(f.nextID)
+	f.objects[resourceType][id] = obj
+	return id
+}
+
+// update changes fields of a stored object behind Terraform's back
+func (f *fakeHSDP) update(resourceType, id string, fields fakeObject) {
+	f.Lock()
+	defer f.Unlock()
+	if obj := f.objects[resourceType][id]; obj != nil {
+		for k, v := range fields {
+			obj[k] = v
+		}
+	}
+}
+
+// fakeCopy returns a shallow copy of obj without the omitted fields, for
+// services which do not report the id or meta of the generic store
+func (f *fakeHSDP) fakeCopy(obj fakeObject, omit ...string) fakeObject {
+	f.Lock()
+	defer f.Unlock()
+	result := fakeObject{}
+	for k, v := range obj {
+		result[k] = v
+	}
+	for _, k := range omit {
+		delete(result, k)
+	}
+	return result
+}
+
+// s3credsPolicies serves the S3 Credentials policies. Policies are kept per
+// product key, which selects the bucket they grant access to
+func (f *fakeHSDP) s3credsPolicies(w http.ResponseWriter, r *http.Request) {
+	productKey := r.Header.Get("X-Product-Key")
+	if productKey == "" || r.Header.Get("Api-Version") != s3creds.CredentialsAPIVersion {
+		f.writeJSON(w, http.StatusBadRequest, fakeObject{"message": "missing product key or API version"})
+		return
+	}
+	resourceType := "S3CredsPolicy/" + productKey
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/core/credentials/Policy"), "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		policy := f.readJSON(r)
+		f.putNumbered(resourceType, policy)
+		f.writeJSON(w, http.StatusCreated, policy)
+	case id == "" && r.Method == http.MethodGet:
+		query := r.URL.Query()
+		f.Lock()
+		ids := make([]string, 0)
+		for id := range f.objects[resourceType] {
+			if query.Get("id") == "" || query.Get("id") == id {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		policies := make([]fakeObject, 0, len(ids))
+		for _, id := range ids {
+			policies = append(policies, f.objects[resourceType][id])
+		}
+		f.Unlock()
+		f.writeJSON(w, http.StatusOK, policies)
+	case id != "" && r.Method == http.MethodDelete:
+		if f.get(resourceType, id) == nil {
+			f.writeJSON(w, http.StatusNotFound, fakeObject{"message": "policy not found"})
+			return
+		}
+		f.remove(resourceType, id)
+		f.writeJSON(w, http.StatusNoContent, nil)
+	default:
+		f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// autoscalers serves the Console autoscaler settings of the apps bound to a
+// metrics instance. The apps are seeded by the tests as Autoscaler objects
+// with ID instance/app, the Console cannot create them
+func (f *fakeHSDP) autoscalers(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		f.writeJSON(w, http.StatusUnauthorized, fakeObject{"status": "error", "error": fakeObject{"code": "UNAUTHORIZED"}})
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v3/metrics/"), "/"), "/")
+	notFound := fakeObject{"status": "error", "error": fakeObject{"code": "NOT_FOUND", "message": "application not found"}}
+
+	switch {
+	case len(parts) == 3 && parts[1] == "autoscalers" && r.Method == http.MethodGet:
+		app := f.get("Autoscaler", parts[0]+"/"+parts[2])
+		if app == nil {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"status": "success",
+			"data":   fakeObject{"application": f.fakeCopy(app, "id", "meta")},
+		})
+	case len(parts) == 2 && parts[1] == "autoscalers" && r.Method == http.MethodPut:
+		settings := f.readJSON(r)
+		id := parts[0] + "/" + fmt.Sprint(settings["name"])
+		if f.get("Autoscaler", id) == nil {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		// Thresholds which are left out keep their settings
+		f.update("Autoscaler", id, settings)
+		f.writeJSON(w, http.StatusOK, fakeObject{
+			"status": "success",
+			"data":   fakeObject{"application": f.fakeCopy(f.get("Autoscaler", id), "id", "meta")},
+		})
+	default:
+		f.writeJSON(w, http.StatusMethodNotAllowed, notFound)
+	}
+}
+
+// pki serves PKI tenants, which are onboarded with the Console token, and the
+// certificates issued by their roles, which use the IAM token. Errors are
+// reported the Vault way, as a list of messages
+func (f *fakeHSDP) pki(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		f.writeJSON(w, http.StatusUnauthorized, fakeObject{"errors": []string{"missing client token"}})
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/core/pki/"), "/"), "/")
+	switch {
+	case parts[0] == "tenant":
+		f.pkiTenant(w, r, parts[1:])
+	case parts[0] == "api" && len(parts) >= 3:
+		f.pkiCertificates(w, r, parts[1], parts[2:])
+	default:
+		f.writeJSON(w, http.StatusNotFound, fakeObject{"errors": []string{"no handler for route"}})
+	}
+}
+
+func (f *fakeHSDP) pkiTenant(w http.ResponseWriter, r *http.Request, parts []string) {
+	notFound := fakeObject{"errors": []string{"tenant not found"}}
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		tenant := f.readJSON(r)
+		logicalPath := strings.ToLower(fmt.Sprintf("%v-%v", tenant["organization_name"], tenant["space_name"]))
+		if f.get("PKITenant", logicalPath) != nil {
+			f.writeJSON(w, http.StatusConflict, fakeObject{"errors": []string{"tenant already onboarded"}})
+			return
+		}
+		f.pkiStoreTenant(logicalPath, tenant)
+		f.writeJSON(w, http.StatusOK, fakeObject{"api_endpoint": "http://" + r.Host + "/core/pki/api/" + logicalPath})
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		tenant := f.get("PKITenant", parts[0])
+		if tenant == nil {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		f.writeJSON(w, http.StatusOK, f.fakeCopy(tenant, "id", "meta"))
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		if f.get("PKITenant", parts[0]) == nil {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		f.pkiStoreTenant(parts[0], f.readJSON(r))
+		f.writeJSON(w, http.StatusNoContent, nil)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if f.get("PKITenant", parts[0]) == nil {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		f.Lock()
+		delete(f.objects["PKITenant"], parts[0])
+		delete(f.objects, "PKICert/"+parts[0])
+		f.Unlock()
+		f.writeJSON(w, http.StatusNoContent, nil)
+	default:
+		f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// pkiStoreTenant keeps tenant under its logical path, which PKI assigns
+func (f *fakeHSDP) pkiStoreTenant(logicalPath string, tenant fakeObject) {
+	if parameters, ok := tenant["service_parameters"].(map[string]interface{}); ok {
+		parameters["logical_path"] = logicalPath
+	}
+	tenant["id"] = logicalPath
+	f.put("PKITenant", tenant)
+}
+
+// pkiRoleOK reports whether the tenant at logicalPath has a role named role
+func (f *fakeHSDP) pkiRoleOK(logicalPath, role string) bool {
+	f.Lock()
+	defer f.Unlock()
+	tenant := f.objects["PKITenant"][logicalPath]
+	if tenant == nil {
+		return false
+	}
+	parameters, _ := tenant["service_parameters"].(map[string]interface{})
+	for _, r := range toInterfaceList(parameters["roles"]) {
+		if m, ok := r.(map[string]interface{}); ok && m["name"] == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeHSDP) pkiCertificates(w http.ResponseWriter, r *http.Request, logicalPath string, parts []string) {
+	resourceType := "PKICert/" + logicalPath
+	if f.get("PKITenant", logicalPath) == nil {
+		f.writeJSON(w, http.StatusNotFound, fakeObject{"errors": []string{"no handler for route '" + logicalPath + "'"}})
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "issue" && r.Method == http.MethodPost:
+		request := f.readJSON(r)
+		if !f.pkiRoleOK(logicalPath, parts[1]) {
+			f.writeJSON(w, http.StatusBadRequest, fakeObject{"errors": []string{"unknown role: " + parts[1]}})
+			return
+		}
+		cert, err := f.issueCertificate(fmt.Sprint(request["common_name"]), fmt.Sprint(request["ttl"]))
+		if err != nil {
+			f.writeJSON(w, http.StatusInternalServerError, fakeObject{"errors": []string{err.Error()}})
+			return
+		}
+		cert["id"] = cert["serial_number"]
+		f.put(resourceType, cert)
+		f.writeJSON(w, http.StatusOK, fakeObject{"request_id": "fake-request", "data": f.fakeCopy(cert, "id", "meta")})
+	case len(parts) == 2 && parts[0] == "cert" && r.Method == http.MethodGet:
+		cert := f.get(resourceType, parts[1])
+		if cert == nil {
+			f.writeJSON(w, http.StatusNotFound, fakeObject{"errors": []string{}})
+			return
+		}
+		// Certificates are read back as issued, without their private key
+		f.writeJSON(w, http.StatusOK, fakeObject{"request_id": "fake-request", "data": f.fakeCopy(cert, "id", "meta", "private_key", "private_key_type")})
+	case len(parts) == 1 && parts[0] == "revoke" && r.Method == http.MethodPost:
+		serial := fmt.Sprint(f.readJSON(r)["serial_number"])
+		if f.get(resourceType, serial) == nil {
+			f.writeJSON(w, http.StatusBadRequest, fakeObject{"errors": []string{"certificate with serial " + serial + " not found"}})
+			return
+		}
+		now := time.Now().UTC()
+		f.update(resourceType, serial, fakeObject{"revocation_time": now.Unix()})
+		f.writeJSON(w, http.StatusOK, fakeObject{"request_id": "fake-request", "data": fakeObject{
+			"revocation_time":         now.Unix(),
+			"revocation_time_rfc3339": now.Format(time.RFC3339),
+		}})
+	default:
+		f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// issueCertificate returns the issue data of a certificate for commonName
+// signed by the CA of the fake, which is created on first use
+func (f *fakeHSDP) issueCertificate(commonName, ttl string) (fakeObject, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.pkiCA == nil {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Fake PKI CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+			IsCA:                  true,
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			return nil, err
+		}
+		f.pkiCA, _ = x509.ParseCertificate(der)
+		f.pkiCAKey = key
+	}
+	validity, err := time.ParseDuration(ttl)
+	if err != nil {
+		validity = 720 * time.Hour
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	f.nextID++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(1000 + f.nextID)),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, f.pkiCA, &key.PublicKey, f.pkiCAKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	// Vault formats serial numbers as colon separated hex bytes
+	serial := make([]string, 0)
+	for _, b := range template.SerialNumber.Bytes() {
+		serial = append(serial, fmt.Sprintf("%02x", b))
+	}
+	issuingCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.pkiCA.Raw}))
+	return fakeObject{
+		"certificate":      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		"issuing_ca":       issuingCA,
+		"ca_chain":         []string{issuingCA},
+		"private_key":      string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		"private_key_type": "ec",
+		"serial_number":    strings.Join(serial, ":"),
+		"expiration":       template.NotAfter.Unix(),
+	}, nil
+}
+
+// fhir serves a CDR FHIR store per root organization at /store/fhir/{root}.
+// Organizations are onboarded with a PUT to their ID, other resources are
+// created with a POST and all of them can be changed with a JSON patch
+func (f *fakeHSDP) fhir(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/store/fhir/"), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		f.writeOutcome(w, http.StatusNotFound, "not-found", "unknown resource "+r.URL.Path)
+		return
+	}
+	resourceType := "FHIR/" + parts[0] + "/" + parts[1]
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		obj := f.readJSON(r)
+		if obj["resourceType"] != parts[1] {
+			f.writeOutcome(w, http.StatusBadRequest, "invalid", "resourceType does not match the URL")
+			return
+		}
+		delete(obj, "id")
+		obj["meta"] = fakeObject{"versionId": "1"}
+		id := f.put(resourceType, obj)
+		w.Header().Set("Location", r.URL.Path+"/"+id+"/_history/1")
+		f.writeJSON(w, http.StatusCreated, obj)
+	case len(parts) == 3 && r.Method == http.MethodPut:
+		obj := f.readJSON(r)
+		obj["id"] = parts[2]
+		obj["meta"] = fakeObject{"versionId": "1"}
+		status := http.StatusOK
+		if f.get(resourceType, parts[2]) == nil {
+			status = http.StatusCreated
+		}
+		f.put(resourceType, obj)
+		f.writeJSON(w, status, obj)
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		obj := f.get(resourceType, parts[2])
+		if obj == nil {
+			f.writeOutcome(w, http.StatusNotFound, "not-found", parts[1]+"/"+parts[2]+" is not known")
+			return
+		}
+		f.writeJSON(w, http.StatusOK, obj)
+	case len(parts) == 3 && r.Method == http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/json-patch+json" {
+			f.writeOutcome(w, http.StatusUnsupportedMediaType, "not-supported", "only JSON patches are supported")
+			return
+		}
+		var ops []fakeObject
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &ops); err != nil {
+			f.writeOutcome(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		f.Lock()
+		obj := f.objects[resourceType][parts[2]]
+		var err error
+		if obj == nil {
+			err = fmt.Errorf("%s/%s is not known", parts[1], parts[2])
+		}
+		for _, op := range ops {
+			if err != nil {
+				break
+			}
+			path := strings.Split(fmt.Sprint(op["path"]), "/")[1:]
+			_, err = fakeJSONPatch(map[string]interface{}(obj), path, fmt.Sprint(op["op"]), op["value"])
+		}
+		if err == nil {
+			meta, _ := obj["meta"].(fakeObject)
+			version, _ := strconv.Atoi(fmt.Sprint(meta["versionId"]))
+			obj["meta"] = fakeObject{"versionId": strconv.Itoa(version + 1)}
+		}
+		f.Unlock()
+		if err != nil {
+			f.writeOutcome(w, http.StatusUnprocessableEntity, "processing", err.Error())
+			return
+		}
+		f.writeJSON(w, http.StatusOK, f.get(resourceType, parts[2]))
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		if f.get(resourceType, parts[2]) == nil {
+			f.writeOutcome(w, http.StatusNotFound, "not-found", parts[1]+"/"+parts[2]+" is not known")
+			return
+		}
+		f.remove(resourceType, parts[2])
+		f.writeJSON(w, http.StatusNoContent, nil)
+	default:
+		f.writeOutcome(w, http.StatusMethodNotAllowed, "not-supported", r.Method+" is not supported")
+	}
+}
+
+// fakeJSONPatch applies a single add, remove or replace operation at the
+// JSON pointer tokens in node and returns the updated node
+func fakeJSONPatch(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("patching the whole resource is not supported")
+	}
+	token := strings.NewReplacer("~1", "/", "~0", "~").Replace(tokens[0])
+	last := len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		_, exists := n[token]
+		switch {
+		case last && op == "add":
+			n[token] = value
+		case !exists:
+			return nil, fmt.Errorf("path element %q not found", token)
+		case last && op == "replace":
+			n[token] = value
+		case last && op == "remove":
+			delete(n, token)
+		case last:
+			return nil, fmt.Errorf("unsupported patch operation %q", op)
+		default:
+			updated, err := fakeJSONPatch(n[token], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[token] = updated
+		}
+		return n, nil
+	case []interface{}:
+		index := len(n)
+		if token != "-" {
+			var err error
+			if index, err = strconv.Atoi(token); err != nil || index < 0 || index > len(n) {
+				return nil, fmt.Errorf("invalid array index %q", token)
+			}
+		}
+		if last && op == "add" {
+			result := append(make([]interface{}, 0, len(n)+1), n[:index]...)
+			return append(append(result, value), n[index:]...), nil
+		}
+		if index == len(n) {
+			return nil, fmt.Errorf("array index %q out of range", token)
+		}
+		switch {
+		case last && op == "replace":
+			n[index] = value
+		case last && op == "remove":
+			return append(append(make([]interface{}, 0, len(n)-1), n[:index]...), n[index+1:]...), nil
+		case last:
+			return nil, fmt.Errorf("unsupported patch operation %q", op)
+		default:
+			updated, err := fakeJSONPatch(n[index], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[index] = updated
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("path element %q not found", token)
+}
+
+// writeOutcome reports an error as a FHIR OperationOutcome
+func (f *fakeHSDP) writeOutcome(w http.ResponseWriter, status int, code, text string) {
+	f.writeJSON(w, status, fakeObject{
+		"resourceType": "OperationOutcome",
+		"issue": []fakeObject{{
+			"severity": "error",
+			"code":     code,
+			"details":  fakeObject{"text": text},
+		}},
+	})
+}
+
+// dicom serves the DICOM configuration of the organization in the
+// organizationId query parameter. Object stores and repositories are
+// collections, the CDR service account and FHIR store are single settings
+func (f *fakeHSDP) dicom(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("API-Version") == "" || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		f.writeJSON(w, http.StatusUnauthorized, fakeObject{"message": "missing token or API version"})
+		return
+	}
+	orgID := r.URL.Query().Get("organizationId")
+	path := strings.TrimPrefix(r.URL.Path, "/dicom-config/store/dicom/config/dicom/production/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	resourceType := "DICOM/" + parts[0]
+	notFound := fakeObject{"message": parts[0] + " not found"}
+	if orgID == "" && !(len(parts) == 1 && r.Method == http.MethodGet && parts[0] == "dicomRepositories") {
+		f.writeJSON(w, http.StatusBadRequest, fakeObject{"message": "organizationId is required"})
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && (parts[0] == "cdrServiceAccount" || parts[0] == "fhirStore"):
+		switch r.Method {
+		case http.MethodPost:
+			setting := f.readJSON(r)
+			setting["id"] = fmt.Sprintf("%s-%s", parts[0], orgID)
+			f.Lock()
+			if f.objects[resourceType] == nil {
+				f.objects[resourceType] = make(map[string]fakeObject)
+			}
+			f.objects[resourceType][orgID] = setting
+			f.Unlock()
+			f.writeJSON(w, http.StatusCreated, setting)
+		case http.MethodGet:
+			if setting := f.get(resourceType, orgID); setting != nil {
+				f.writeJSON(w, http.StatusOK, setting)
+				return
+			}
+			f.writeJSON(w, http.StatusNotFound, notFound)
+		default:
+			f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+		}
+	case len(parts) == 1 && (parts[0] == "objectStores" || parts[0] == "dicomRepositories"):
+		switch r.Method {
+		case http.MethodPost:
+			obj := f.readJSON(r)
+			delete(obj, "id")
+			obj["_organizationId"] = orgID
+			f.put(resourceType, obj)
+			f.writeJSON(w, http.StatusCreated, f.fakeCopy(obj, "meta", "_organizationId"))
+		case http.MethodGet:
+			f.Lock()
+			list := make([]fakeObject, 0)
+			for _, obj := range f.objects[resourceType] {
+				if orgID == "" || obj["_organizationId"] == orgID {
+					list = append(list, obj)
+				}
+			}
+			f.Unlock()
+			for i, obj := range list {
+				list[i] = f.fakeCopy(obj, "meta", "_organizationId")
+			}
+			f.writeJSON(w, http.StatusOK, list)
+		default:
+			f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+		}
+	case len(parts) == 2 && (parts[0] == "objectStores" || parts[0] == "dicomRepositories"):
+		obj := f.get(resourceType, parts[1])
+		if obj == nil || obj["_organizationId"] != orgID {
+			f.writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			f.writeJSON(w, http.StatusOK, f.fakeCopy(obj, "meta", "_organizationId"))
+		case http.MethodDelete:
+			f.remove(resourceType, parts[1])
+			f.writeJSON(w, http.StatusNoContent, nil)
+		default:
+			f.writeJSON(w, http.StatusMethodNotAllowed, nil)
+		}
+	default:
+		f.writeJSON(w, http.StatusNotFound, notFound)
+	}
+}
+
+// stlField is a field of a GraphQL selection set with its arguments
+type stlField struct {
+	name      string
+	args      map[string]string
+	selection []stlField
+}
+
+// parseSTLSelection parses the selection set starting at q[*i], which is the
+// opening brace. Only the compact form written by the GraphQL client is
+// understood
+func parseSTLSelection(q string, i *int) []stlField {
+	var fields []stlField
+	*i++
+	for *i < len(q) && q[*i] != '}' {
+		if q[*i] == ',' || q[*i] == ' ' {
+			*i++
+			continue
+		}
+		start := *i
+		for *i < len(q) && !strings.ContainsRune("(){}, ", rune(q[*i])) {
+			*i++
+		}
+		field := stlField{name: q[start:*i], args: map[string]string{}}
+		if *i < len(q) && q[*i] == '(' {
+			end := *i + strings.IndexByte(q[*i:], ')')
+			for _, arg := range strings.Split(q[*i+1:end], ",") {
+				if kv := strings.SplitN(arg, ":", 2); len(kv) == 2 {
+					field.args[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+				}
+			}
+			*i = end + 1
+		}
+		if *i < len(q) && q[*i] == '{' {
+			field.selection = parseSTLSelection(q, i)
+		}
+		fields = append(fields, field)
+	}
+	*i++
+	return fields
+}
+
+// stlProject returns the part of v asked for by selection
+func stlProject(v interface{}, selection []stlField) interface{} {
+	if len(selection) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case fakeObject:
+		return stlProject(map[string]interface{}(v), selection)
+	case map[string]interface{}:
+		result := fakeObject{}
+		for _, field := range selection {
+			result[field.name] = stlProject(v[field.name], field.selection)
+		}
+		return result
+	case []fakeObject:
+		result := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			result = append(result, stlProject(e, selection))
+		}
+		return result
+	}
+	return v
+}
+
+// stlTypes maps the STL objects managed per device to their fake type
+var stlTypes = map[string]string{
+	"applicationResource": "STLApp",
+	"appCustomCert":       "STLCert",
+}
+
+// stl serves the STL GraphQL API. The top level fields are resolved one by
+// one and the results projected on their selection set, as the client
+// rejects fields it did not ask for. Devices are seeded by the tests as
+// STLDevice objects, their firewall exceptions, logging config and number
+// of syncs are kept on the device
+func (f *fakeHSDP) stl(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		f.writeJSON(w, http.StatusUnauthorized, fakeObject{"errors": []fakeObject{{"message": "unauthorized"}}})
+		return
+	}
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	start := -1
+	if err := json.NewDecoder(r.Body).Decode(&request); err == nil {
+		start = strings.IndexByte(request.Query, '{')
+	}
+	if start < 0 {
+		f.writeJSON(w, http.StatusBadRequest, fakeObject{"errors": []fakeObject{{"message": "invalid request"}}})
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	data := fakeObject{}
+	var errs []fakeObject
+	for _, field := range parseSTLSelection(request.Query, &start) {
+		args := fakeObject{}
+		for name, value := range field.args {
+			if strings.HasPrefix(value, "$") {
+				args[name] = request.Variables[value[1:]]
+			}
+		}
+		result, err := f.stlResolveLocked(field.name, args)
+		if err != nil {
+			data[field.name] = nil
+			errs = append(errs, fakeObject{"message": err.Error(), "path": []string{field.name}})
+			continue
+		}
+		data[field.name] = stlProject(result, field.selection)
+	}
+	response := fakeObject{"data": data}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	f.writeJSON(w, http.StatusOK, response)
+}
+
+// stlResolveLocked resolves a top level query or mutation field. Failed
+// mutations are reported in their payload, failed queries as GraphQL errors
+func (f *fakeHSDP) stlResolveLocked(field string, args fakeObject) (interface{}, error) {
+	input, _ := args["input"].(map[string]interface{})
+	payload := func(status int, message string) fakeObject {
+		return fakeObject{
+			"success":    status < 300,
+			"statusCode": status,
+			"message":    message,
+			"requestId":  fmt.Sprintf("fake-%d", status),
+		}
+	}
+
+	switch field {
+	case "device":
+		var device fakeObject
+		if serial, ok := args["serialNumber"]; ok {
+			device = f.stlDeviceLocked(serial)
+		} else {
+			device = f.objects["STLDevice"][stlID(args["id"])]
+		}
+		if device == nil {
+			return nil, fmt.Errorf("device not found")
+		}
+		return device, nil
+	case "syncDeviceConfigs":
+		device := f.stlDeviceLocked(input["serialNumber"])
+		if device == nil {
+			return payload(http.StatusNotFound, "device not found"), nil
+		}
+		syncs, _ := device["syncs"].(int)
+		device["syncs"] = syncs + 1
+		return payload(http.StatusOK, "OK"), nil
+	case "appFirewallException", "appLogging":
+		device := f.stlDeviceLocked(args["serialNumber"])
+		if device == nil {
+			return nil, fmt.Errorf("device not found")
+		}
+		if config := device[field]; config != nil {
+			return config, nil
+		}
+		if field == "appFirewallException" {
+			return fakeObject{"deviceId": device["id"], "tcp": []int{}, "udp": []int{}}, nil
+		}
+		return fakeObject{"deviceId": device["id"], "rawConfig": "", "hsdpLogging": false}, nil
+	case "updateAppFirewallException", "updateAppLogging":
+		device := f.stlDeviceLocked(input["serialNumber"])
+		if device == nil {
+			return payload(http.StatusNotFound, "device not found"), nil
+		}
+		// The whole config is replaced
+		config := fakeObject{"deviceId": device["id"]}
+		for k, v := range input {
+			if k != "serialNumber" {
+				config[k] = v
+			}
+		}
+		name := "a" + strings.TrimPrefix(field, "updateA")
+		device[name] = config
+		result := payload(http.StatusOK, "OK")
+		result[name] = config
+		return result, nil
+	case "applicationResource", "appCustomCert":
+		obj := f.objects[stlTypes[field]][stlID(args["id"])]
+		if obj == nil {
+			return nil, fmt.Errorf("%s not found", field)
+		}
+		return obj, nil
+	case "createApplicationResource", "createAppCustomCert",
+		"updateApplicationResource", "updateAppCustomCert",
+		"deleteApplicationResource", "deleteAppCustomCert":
+		verb := field[:6]
+		name := strings.ToLower(field[6:7]) + field[7:]
+		resourceType := stlTypes[name]
+		var obj fakeObject
+		if verb == "create" {
+			device := f.stlDeviceLocked(input["serialNumber"])
+			if device == nil {
+				return payload(http.StatusNotFound, "device not found"), nil
+			}
+			obj = fakeObject{"deviceId": device["id"]}
+			f.putNumberedLocked(resourceType, obj)
+		} else if obj = f.objects[resourceType][stlID(input["id"])]; obj == nil {
+			return payload(http.StatusNotFound, name+" not found"), nil
+		}
+		if verb == "delete" {
+			delete(f.objects[resourceType], stlID(obj["id"]))
+			return payload(http.StatusOK, "OK"), nil
+		}
+		// The device of an object can not be changed
+		for k, v := range input {
+			if k != "id" && k != "deviceId" && k != "serialNumber" && k != "groupId" {
+				obj[k] = v
+			}
+		}
+		result := payload(http.StatusOK, "OK")
+		result[name] = obj
+		return result, nil
+	}
+	return nil, fmt.Errorf("cannot query field %q", field)
+}
+
+// stlDeviceLocked returns the device with the serial number, or nil
+func (f *fakeHSDP) stlDeviceLocked(serial interface{}) fakeObject {
+	for _, device := range f.objects["STLDevice"] {
+		if device["serialNumber"] == serial {
+			return device
+		}
+	}
+	return nil
+}
+
+// stlID returns the store ID of a numeric GraphQL ID
+func stlID(id interface{}) string {
+	if n, ok := id.(float64); ok {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return fmt.Sprint(id)
+}
@@ -0,0 +1,248 @@
+package hsdp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeSSHHost is what the fake knows of a container host reached over SSH:
+// the commands it ran, in order, and the files copied to it by destination
+type fakeSSHHost struct {
+	via      string
+	commands []string
+	files    map[string]string
+}
+
+// withProxy answers the CONNECT requests of SSH connections going through
+// the provider http_proxy, which is the fake itself. Other requests go to next
+func (f *fakeHSDP) withProxy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			next.ServeHTTP(w, r)
+			return
+		}
+		host, port, err := net.SplitHostPort(r.Host)
+		if err != nil || port != "22" {
+			f.writeJSON(w, http.StatusBadGateway, nil)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		_, _ = rw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+		_ = rw.Flush()
+		// The client reads the response through a buffer of its own, which
+		// would swallow an SSH banner sent along with it. Wait for its banner
+		if _, err := rw.Peek(1); err != nil {
+			_ = conn.Close()
+			return
+		}
+		go f.serveSSH(&fakeBufferedConn{Conn: conn, reader: rw.Reader}, host, "")
+	})
+}
+
+// sshKey returns the private key in PEM format the fake SSH servers accept
+func (f *fakeHSDP) sshKey() string {
+	f.Lock()
+	defer f.Unlock()
+	if f.sshConfig == nil {
+		clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		der, _ := x509.MarshalECPrivateKey(clientKey)
+		f.sshPrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+		authorized, _ := ssh.NewPublicKey(&clientKey.PublicKey)
+
+		hostKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		hostSigner, _ := ssh.NewSignerFromKey(hostKey)
+		f.sshConfig = &ssh.ServerConfig{
+			PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+				if !bytes.Equal(key.Marshal(), authorized.Marshal()) {
+					return nil, fmt.Errorf("unknown key for %s", conn.User())
+				}
+				return nil, nil
+			},
+		}
+		f.sshConfig.AddHostKey(hostSigner)
+		f.sshHosts = make(map[string]*fakeSSHHost)
+	}
+	return f.sshPrivateKey
+}
+
+// sshHost returns a copy of what host saw, or nil when nobody connected to it
+func (f *fakeHSDP) sshHost(host string) *fakeSSHHost {
+	f.Lock()
+	defer f.Unlock()
+	h := f.sshHosts[host]
+	if h == nil {
+		return nil
+	}
+	result := &fakeSSHHost{via: h.via, commands: append([]string(nil), h.commands...), files: map[string]string{}}
+	for k, v := range h.files {
+		result.files[k] = v
+	}
+	return result
+}
+
+// serveSSH serves an SSH connection to host. A host reached through the
+// proxy acts as bastion and only forwards connections, the hosts behind it
+// run commands and accept files copied with scp
+func (f *fakeHSDP) serveSSH(conn net.Conn, host, via string) {
+	defer func() { _ = conn.Close() }()
+	f.Lock()
+	config := f.sshConfig
+	f.Unlock()
+	if config == nil {
+		return
+	}
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		switch {
+		case newChannel.ChannelType() == "direct-tcpip" && via == "":
+			var target struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil || target.Port != 22 {
+				_ = newChannel.Reject(ssh.ConnectionFailed, "connection refused")
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go ssh.DiscardRequests(requests)
+			go f.serveSSH(&fakeChannelConn{Channel: channel}, target.Host, host)
+		case newChannel.ChannelType() == "session" && via != "":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go f.serveSSHSession(channel, requests, host, via)
+		default:
+			_ = newChannel.Reject(ssh.Prohibited, "not supported by the fake")
+		}
+	}
+}
+
+// serveSSHSession runs the exec request of a session. The command false
+// fails, like it does in a shell
+func (f *fakeHSDP) serveSSHSession(channel ssh.Channel, requests <-chan *ssh.Request, host, via string) {
+	defer func() { _ = channel.Close() }()
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		var exec struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &exec); err != nil {
+			_ = req.Reply(false, nil)
+			return
+		}
+		_ = req.Reply(true, nil)
+
+		status := 0
+		if destination := strings.TrimPrefix(exec.Command, "scp -tr "); destination != exec.Command {
+			content, err := readSCPFile(channel)
+			if err != nil {
+				_, _ = fmt.Fprintf(channel.Stderr(), "scp: %v\n", err)
+				status = 1
+			} else {
+				f.recordSSH(host, via, "", destination, content)
+			}
+		} else {
+			f.recordSSH(host, via, exec.Command, "", "")
+			_, _ = fmt.Fprintf(channel, "ran %s on %s\n", exec.Command, host)
+			if exec.Command == "false" {
+				status = 1
+			}
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+		return
+	}
+}
+
+// recordSSH records a command run on or a file copied to host
+func (f *fakeHSDP) recordSSH(host, via, command, destination, content string) {
+	f.Lock()
+	defer f.Unlock()
+	h := f.sshHosts[host]
+	if h == nil {
+		h = &fakeSSHHost{via: via, files: map[string]string{}}
+		f.sshHosts[host] = h
+	}
+	if command != "" {
+		h.commands = append(h.commands, command)
+	}
+	if destination != "" {
+		h.files[destination] = content
+	}
+}
+
+// readSCPFile reads a single file sent in the scp sink protocol as written
+// by easyssh: a C0644 header line, the content and a closing zero byte
+func readSCPFile(r io.Reader) (string, error) {
+	reader := bufio.NewReader(r)
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 || !strings.HasPrefix(fields[0], "C") {
+		return "", fmt.Errorf("unexpected header %q", header)
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", err
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(reader, size))
+	if err != nil {
+		return "", err
+	}
+	if end, err := reader.ReadByte(); err != nil || end != 0 {
+		return "", fmt.Errorf("file of %d bytes not terminated", size)
+	}
+	return string(content), nil
+}
+
+// fakeBufferedConn reads a hijacked connection through the buffer of the server
+type fakeBufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *fakeBufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// fakeChannelConn makes a forwarded SSH channel usable as connection
+type fakeChannelConn struct {
+	ssh.Channel
+}
+
+func (c *fakeChannelConn) LocalAddr() net.Addr                { return &net.TCPAddr{} }
+func (c *fakeChannelConn) RemoteAddr() net.Addr               { return &net.TCPAddr{} }
+func (c *fakeChannelConn) SetDeadline(_ time.Time) error      { return nil }
+func (c *fakeChannelConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (c *fakeChannelConn) SetWriteDeadline(_ time.Time) error { return nil }
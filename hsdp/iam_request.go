@@ -0,0 +1,95 @@
+package hsdp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/philips-software/go-hsdp-api/config"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+// idmURL returns the base URL of IAM identity management
+func (c *Config) idmURL() (string, error) {
+	return c.serviceURL(c.IDMURL, "idm", "idm_url")
+}
+
+// iamURL returns the base URL of IAM access management
+func (c *Config) iamURL() (string, error) {
+	return c.serviceURL(c.IAMURL, "iam", "iam_url")
+}
+
+// serviceURL returns configured or else the URL of service in the configured
+// region and environment. argument names the provider argument to set
+func (c *Config) serviceURL(configured, service, argument string) (string, error) {
+	if configured != "" {
+		return strings.TrimRight(configured, "/"), nil
+	}
+	hsdpConfig, err := config.New(config.WithEnv(c.Environment), config.WithRegion(c.Region))
+	if err != nil {
+		return "", err
+	}
+	serviceURL := hsdpConfig.Service(service).URL
+	if serviceURL == "" {
+		return "", fmt.Errorf("no %s URL known for region %q and environment %q, set %s",
+			strings.ToUpper(service), c.Region, c.Environment, argument)
+	}
+	return strings.TrimRight(serviceURL, "/"), nil
+}
+
+// iamRequest calls an IAM endpoint which go-hsdp-api does not cover. path is
+// relative to the IDM URL, body is sent as JSON and the response is decoded
+// into result unless it is nil
+func (c *Config) iamRequest(ctx context.Context, client *iam.Client, method, path string, body, result interface{}) (*http.Response, error) {
+	return c.iamRequestVersion(ctx, client, "1", method, path, body, result)
+}
+
+// iamRequestVersion is iamRequest for endpoints which need another API version
+func (c *Config) iamRequestVersion(ctx context.Context, client *iam.Client, apiVersion, method, path string, body, result interface{}) (*http.Response, error) {
+	idmURL, err := c.idmURL()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.iamSessions.renewExpiring(client); err != nil {
+		return nil, err
+	}
+	token := client.Token()
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, idmURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Api-Version", apiVersion)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if result == nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return resp, nil
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,30 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importStateWithFields returns an importer for resources which cannot be read
+// by their ID alone, e.g. because the URL of the service they live in is an
+// argument. The import ID lists the values of fields followed by the ID of the
+// resource, separated by commas as the fields are often URLs
+func importStateWithFields(fields ...string) schema.StateContextFunc {
+	return func(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.Split(d.Id(), ",")
+		if len(parts) != len(fields)+1 {
+			return nil, fmt.Errorf("%w: %q, expected %s,id", ErrInvalidImportID, d.Id(), strings.Join(fields, ","))
+		}
+		for i, field := range fields {
+			if parts[i] == "" {
+				return nil, fmt.Errorf("%w: %q, missing %s", ErrInvalidImportID, d.Id(), field)
+			}
+			_ = d.Set(field, parts[i])
+		}
+		d.SetId(parts[len(fields)])
+		return []*schema.ResourceData{d}, nil
+	}
+}
@@ -0,0 +1,45 @@
+package hsdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// testImportID returns the import ID of name, prefixed with the given field values
+func testImportID(name string, fields ...string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return "", fmt.Errorf("%s not found in state", name)
+		}
+		return strings.Join(append(fields, rs.Primary.ID), ","), nil
+	}
+}
+
+func TestImportStateWithFields(t *testing.T) {
+	r := resourceDICOMRepository()
+	importer := importStateWithFields("config_url", "organization_id")
+
+	d := r.Data(&terraform.InstanceState{ID: "https://dicom.example.com/config,org-1,repo-1"})
+	result, err := importer(context.Background(), d, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []*schema.ResourceData{d}, result)
+	assert.Equal(t, "repo-1", d.Id())
+	assert.Equal(t, "https://dicom.example.com/config", d.Get("config_url"))
+	assert.Equal(t, "org-1", d.Get("organization_id"))
+
+	for _, id := range []string{"repo-1", "org-1,repo-1", ",org-1,repo-1"} {
+		_, err = importer(context.Background(), r.Data(&terraform.InstanceState{ID: id}), nil)
+		assert.True(t, errors.Is(err, ErrInvalidImportID), id)
+	}
+}
@@ -0,0 +1,187 @@
+package hsdp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultProfileName = "default"
+
+// profile holds the provider settings of a named section in the credentials file.
+// Keys are the provider argument names, e.g. service_id or cartel_token
+type profile map[string]interface{}
+
+// defaultCredentialsFile returns ~/.hsdp/credentials, or an empty string when
+// the home directory cannot be determined
+func defaultCredentialsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".hsdp", "credentials")
+}
+
+// loadProfile reads the named profile from the YAML credentials file. When no
+// profile is requested the default profile is used if present. A missing file
+// or section is only an error when a profile was explicitly requested
+func loadProfile(filename, name string) (profile, error) {
+	explicit := name != ""
+	if !explicit {
+		name = defaultProfileName
+	}
+	if filename == "" {
+		filename = defaultCredentialsFile()
+	}
+	if filename == "" {
+		if explicit {
+			return nil, fmt.Errorf("profile %q: %w", name, ErrMissingCredentialsFile)
+		}
+		return profile{}, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return profile{}, nil
+		}
+		return nil, fmt.Errorf("reading credentials file %s: %w", filename, err)
+	}
+	var profiles map[string]profile
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parsing credentials file %s: %w", filename, err)
+	}
+	p, ok := profiles[name]
+	if !ok || p == nil {
+		if explicit {
+			return nil, fmt.Errorf("profile %q in %s: %w", name, filename, ErrMissingProfile)
+		}
+		return profile{}, nil
+	}
+	return p, nil
+}
+
+// isSet reports whether key is set as an argument, an HSDP_* environment
+// variable or in the profile
+func (p profile) isSet(d *schema.ResourceData, key string) bool {
+	if _, ok := d.GetOk(key); ok {
+		return true
+	}
+	v, ok := p[key]
+	return ok && v != nil && v != ""
+}
+
+// providerArgumentRules are the provider argument combinations validate checks.
+// They are not declared as ConflictsWith and RequiredWith on the schema, as
+// Terraform checks those before the profile is merged in
+var providerArgumentRules = map[string]struct {
+	conflictsWith []string
+	requiredWith  []string
+}{
+	"environment":         {requiredWith: []string{"region"}},
+	"service_id":          {conflictsWith: []string{"org_admin_username"}, requiredWith: []string{"service_private_key"}},
+	"service_private_key": {conflictsWith: []string{"org_admin_password"}, requiredWith: []string{"service_id"}},
+	"org_admin_username":  {conflictsWith: []string{"service_id"}, requiredWith: []string{"org_admin_password"}},
+	"org_admin_password":  {conflictsWith: []string{"service_private_key"}, requiredWith: []string{"org_admin_username"}},
+	"uaa_username":        {requiredWith: []string{"uaa_password"}},
+	"uaa_password":        {requiredWith: []string{"uaa_username"}},
+	"client_cert":         {requiredWith: []string{"client_key"}},
+	"client_key":          {requiredWith: []string{"client_cert"}},
+}
+
+// validate checks providerArgumentRules against the merged configuration of
+// arguments, HSDP_* environment variables and the profile
+func (p profile) validate(d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	keys := make([]string, 0, len(providerArgumentRules))
+	for key := range providerArgumentRules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !p.isSet(d, key) {
+			continue
+		}
+		for _, other := range providerArgumentRules[key].conflictsWith {
+			// Report each conflicting pair once
+			if other < key || !p.isSet(d, other) {
+				continue
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%q conflicts with %s", key, other),
+				Detail:        "Check the provider arguments, HSDP_* environment variables and the selected profile",
+				AttributePath: cty.GetAttrPath(key),
+			})
+		}
+		var missing []string
+		for _, other := range providerArgumentRules[key].requiredWith {
+			if !p.isSet(d, other) {
+				missing = append(missing, other)
+			}
+		}
+		if len(missing) > 0 {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%q: all of `%s` must be specified", key, strings.Join(append([]string{key}, missing...), ",")),
+				Detail:        "Check the provider arguments, HSDP_* environment variables and the selected profile",
+				AttributePath: cty.GetAttrPath(key),
+			})
+		}
+	}
+	return diags
+}
+
+// getString returns the configured value of key, falling back to the profile.
+// Explicit arguments and HSDP_* environment variables take precedence. The SDK
+// does not tell an empty string apart from an unset argument, so "" cannot
+// override a profile value
+func (p profile) getString(d *schema.ResourceData, key string) string {
+	if v, ok := d.GetOk(key); ok {
+		return v.(string)
+	}
+	if v, ok := p[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// getBool returns the configured value of key, falling back to the profile and
+// then to providerDefaults. GetOkExists is used so an explicit false in HCL or
+// the environment overrides the profile
+func (p profile) getBool(d *schema.ResourceData, key string) bool {
+	if v, ok := d.GetOkExists(key); ok {
+		return v.(bool)
+	}
+	if v, ok := p[key].(bool); ok {
+		return v
+	}
+	v, _ := providerDefaults[key].(bool)
+	return v
+}
+
+// getInt behaves like getBool, an explicit 0 overrides the profile
+func (p profile) getInt(d *schema.ResourceData, key string) int {
+	if v, ok := d.GetOkExists(key); ok {
+		return v.(int)
+	}
+	if v, ok := p[key].(int); ok {
+		return v
+	}
+	v, _ := providerDefaults[key].(int)
+	return v
+}
+
+func (p profile) getIntList(key string) []int {
+	list, ok := p[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	return expandIntList(list)
+}
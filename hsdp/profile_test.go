@@ -0,0 +1,234 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCredentials = `
+default:
+  region: us-east
+  environment: client-test
+eu-prod:
+  region: eu-west
+  environment: prod
+  service_id: svc@org.app.prop.philips-healthsuite.com
+  retry_max: 3
+`
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(filename, []byte(testCredentials), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	p, err := loadProfile(filename, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "us-east", p["region"])
+
+	p, err = loadProfile(filename, "eu-prod")
+	assert.Nil(t, err)
+	assert.Equal(t, "eu-west", p["region"])
+	assert.Equal(t, 3, p["retry_max"])
+
+	_, err = loadProfile(filename, "missing")
+	assert.True(t, errors.Is(err, ErrMissingProfile))
+
+	p, err = loadProfile(filepath.Join(dir, "nonexistent"), "")
+	assert.Nil(t, err)
+	assert.Empty(t, p)
+
+	_, err = loadProfile(filepath.Join(dir, "nonexistent"), "eu-prod")
+	assert.NotNil(t, err)
+}
+
+const testPrecedenceCredentials = `
+default:
+  region: us-east
+tuned:
+  retry_max: 5
+  retry_wait_max: 10
+  cartel_skip_verify: false
+`
+
+func testConfigureDiags(t *testing.T, raw map[string]interface{}) (interface{}, diag.Diagnostics) {
+	provider := Provider("test")
+	d := schema.TestResourceDataRaw(t, provider.Schema, raw)
+	return provider.ConfigureContextFunc(context.Background(), d)
+}
+
+func testConfigure(t *testing.T, raw map[string]interface{}) *Config {
+	meta, diags := testConfigureDiags(t, raw)
+	if diags.HasError() {
+		t.Fatalf("configure: %v", diags)
+	}
+	return meta.(*Config)
+}
+
+func TestProviderConfigurePrecedence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte(testPrecedenceCredentials), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Defaults
+	config := testConfigure(t, map[string]interface{}{
+		"credentials_file": filename,
+	})
+	assert.Equal(t, 3, config.RetryMax)
+	assert.Equal(t, 30*time.Second, config.RetryWaitMax)
+	assert.True(t, config.CartelSkipVerify)
+
+	// A CA bundle does not change the Cartel default
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	config = testConfigure(t, map[string]interface{}{
+		"credentials_file": filename,
+		"ca_bundle":        caBundle,
+	})
+	assert.True(t, config.CartelSkipVerify)
+
+	// which is pointed out when Cartel is configured
+	_, diags := testConfigureDiags(t, map[string]interface{}{
+		"credentials_file": filename,
+		"ca_bundle":        caBundle,
+		"cartel_token":     "token",
+		"cartel_secret":    "secret",
+	})
+	if assert.Equal(t, 1, len(diags)) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Contains(t, diags[0].Summary, "Cartel")
+	}
+	_, diags = testConfigureDiags(t, map[string]interface{}{
+		"credentials_file":   filename,
+		"ca_bundle":          caBundle,
+		"cartel_token":       "token",
+		"cartel_secret":      "secret",
+		"cartel_skip_verify": false,
+	})
+	assert.Equal(t, 0, len(diags))
+
+	// Profile over defaults, including false and 0
+	config = testConfigure(t, map[string]interface{}{
+		"credentials_file": filename,
+		"profile":          "tuned",
+	})
+	assert.Equal(t, 5, config.RetryMax)
+	assert.Equal(t, 10*time.Second, config.RetryWaitMax)
+	assert.Equal(t, 1*time.Second, config.RetryWaitMin)
+	assert.False(t, config.CartelSkipVerify)
+
+	// Environment over profile
+	t.Setenv("HSDP_RETRY_MAX", "7")
+	config = testConfigure(t, map[string]interface{}{
+		"credentials_file": filename,
+		"profile":          "tuned",
+	})
+	assert.Equal(t, 7, config.RetryMax)
+
+	// HCL over environment and profile, explicit zero values included
+	config = testConfigure(t, map[string]interface{}{
+		"credentials_file":   filename,
+		"profile":            "tuned",
+		"retry_max":          0,
+		"cartel_skip_verify": true,
+	})
+	assert.Equal(t, 0, config.RetryMax)
+	assert.True(t, config.CartelSkipVerify)
+
+	config = testConfigure(t, map[string]interface{}{
+		"credentials_file": filename,
+		"retry_max":        0,
+		"cartel_no_tls":    false,
+	})
+	assert.Equal(t, 0, config.RetryMax)
+	assert.False(t, config.CartelNoTLS)
+}
+
+const testIncompleteCredentials = `
+service:
+  service_id: svc@org.app.prop.philips-healthsuite.com
+complete:
+  service_id: svc@org.app.prop.philips-healthsuite.com
+  service_private_key: key
+`
+
+func TestProviderConfigureValidatesProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte(testIncompleteCredentials), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Required combinations apply to profile values
+	_, diags := testConfigureDiags(t, map[string]interface{}{
+		"credentials_file": filename,
+		"profile":          "service",
+	})
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, "service_private_key")
+	}
+
+	// The key may come from the environment
+	t.Setenv("HSDP_SERVICE_PRIVATE_KEY", "key")
+	_, diags = testConfigureDiags(t, map[string]interface{}{
+		"credentials_file": filename,
+		"profile":          "service",
+	})
+	assert.False(t, diags.HasError())
+
+	// Conflicts apply across arguments and profile values
+	_, diags = testConfigureDiags(t, map[string]interface{}{
+		"credentials_file":   filename,
+		"profile":            "complete",
+		"org_admin_username": "admin",
+		"org_admin_password": "secret",
+	})
+	if assert.Equal(t, 2, len(diags)) {
+		assert.Contains(t, diags[0].Summary, "conflicts with")
+		assert.Contains(t, diags[1].Summary, "conflicts with")
+	}
+}
+
+func TestProviderValidateLeavesCombinationsToProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte(testIncompleteCredentials), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	raw := map[string]interface{}{
+		"credentials_file": filename,
+		"profile":          "complete",
+		"service_id":       "svc@org.app.prop.philips-healthsuite.com",
+	}
+
+	// The private key is in the profile, which Terraform does not see
+	provider := Provider("test")
+	diags := provider.Validate(terraform.NewResourceConfigRaw(raw))
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	diags = provider.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	// Without the profile the combination is still rejected, at configure time
+	delete(raw, "profile")
+	diags = provider.Validate(terraform.NewResourceConfigRaw(raw))
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	diags = Provider("test").Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, "service_private_key")
+	}
+}
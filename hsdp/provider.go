@@ -2,203 +2,347 @@ package hsdp
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/fhir/go/jsonformat"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"os"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"time"
 )
 
 // Provider returns an instance of the HSDP provider
 func Provider(build string) *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["profile"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_PROFILE", nil),
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["credentials_file"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CREDENTIALS_FILE", nil),
+			},
 			"region": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["region"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_REGION", nil),
 			},
 			"environment": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Description:  descriptions["environment"],
-				RequiredWith: []string{"region"},
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["environment"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_ENVIRONMENT", nil),
 			},
 			"iam_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["iam_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_IAM_URL", nil),
 			},
 			"idm_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["idm_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_IDM_URL", nil),
 			},
 			"s3creds_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["s3creds_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_S3CREDS_URL", nil),
+			},
+			"console_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["console_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CONSOLE_URL", nil),
+			},
+			"pki_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["pki_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_PKI_URL", nil),
+			},
+			"stl_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["stl_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_STL_URL", nil),
 			},
 			"service_id": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"org_admin_username"},
-				RequiredWith:  []string{"service_private_key"},
-				Description:   descriptions["service_id"],
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["service_id"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_SERVICE_ID", nil),
 			},
 			"service_private_key": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				Sensitive:     true,
-				ConflictsWith: []string{"org_admin_password"},
-				RequiredWith:  []string{"service_id"},
-				Description:   descriptions["service_private_key"],
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["service_private_key"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_SERVICE_PRIVATE_KEY", nil),
 			},
 			"oauth2_client_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["oauth2_client_id"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_OAUTH2_CLIENT_ID", nil),
 			},
 			"oauth2_password": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
 				Description: descriptions["oauth2_password"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_OAUTH2_PASSWORD", nil),
 			},
 			"org_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "",
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_ORG_ID", ""),
 				Description: descriptions["org_id"],
 				Deprecated:  "org_id is not used anywhere and should be removed",
 			},
 			"org_admin_username": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				Description:   descriptions["org_admin_username"],
-				RequiredWith:  []string{"org_admin_password"},
-				ConflictsWith: []string{"service_id"},
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["org_admin_username"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_ORG_ADMIN_USERNAME", nil),
 			},
 			"org_admin_password": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				Sensitive:     true,
-				Description:   descriptions["org_admin_password"],
-				RequiredWith:  []string{"org_admin_username"},
-				ConflictsWith: []string{"service_private_key"},
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["org_admin_password"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_ORG_ADMIN_PASSWORD", nil),
 			},
 			"uaa_username": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Description:  descriptions["uaa_username"],
-				RequiredWith: []string{"uaa_password"},
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["uaa_username"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_UAA_USERNAME", nil),
 			},
 			"uaa_password": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				Sensitive:    true,
-				Description:  descriptions["uaa_password"],
-				RequiredWith: []string{"uaa_username"},
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["uaa_password"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_UAA_PASSWORD", nil),
 			},
 			"uaa_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["uaa_url"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_UAA_URL", nil),
 			},
 			"shared_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   false,
 				Description: descriptions["shared_key"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_SHARED_KEY", nil),
 			},
 			"secret_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
 				Description: descriptions["secret_key"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_SECRET_KEY", nil),
 			},
 			"cartel_host": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["cartel_host"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CARTEL_HOST", nil),
 			},
 			"cartel_token": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
 				Description: descriptions["cartel_token"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CARTEL_TOKEN", nil),
 			},
 			"cartel_secret": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
 				Description: descriptions["cartel_secret"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CARTEL_SECRET", nil),
 			},
 			"cartel_no_tls": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     false,
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CARTEL_NO_TLS", nil),
 				Description: descriptions["cartel_no_tls"],
 			},
 			"cartel_skip_verify": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     true,
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CARTEL_SKIP_VERIFY", nil),
 				Description: descriptions["cartel_skip_verify"],
 			},
+			"ca_bundle": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["ca_bundle"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CA_BUNDLE", nil),
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["client_cert"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CLIENT_CERT", nil),
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["client_key"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_CLIENT_KEY", nil),
+			},
+			"http_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["http_proxy"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_HTTP_PROXY", nil),
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["no_proxy"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_NO_PROXY", nil),
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: descriptions["insecure_skip_verify"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_INSECURE_SKIP_VERIFY", nil),
+			},
 			"retry_max": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     0,
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_RETRY_MAX", nil),
 				Description: descriptions["retry_max"],
 			},
+			"retry_wait_min": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_RETRY_WAIT_MIN", nil),
+				Description: descriptions["retry_wait_min"],
+			},
+			"retry_wait_max": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_RETRY_WAIT_MAX", nil),
+				Description: descriptions["retry_wait_max"],
+			},
+			"retry_status_codes": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: descriptions["retry_status_codes"],
+			},
+			"rate_limit": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: descriptions["rate_limit"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(rateLimitServices, false),
+						},
+						"requests_per_second": {
+							Type:         schema.TypeFloat,
+							Optional:     true,
+							ValidateFunc: validation.FloatAtLeast(0),
+						},
+						"max_in_flight": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
 			"debug": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: descriptions["debug"],
 				Deprecated:  "no need to set debug boolean, setting debug_log is sufficient",
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_DEBUG", nil),
 			},
 			"debug_log": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: descriptions["debug_log"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_DEBUG_LOG", nil),
+			},
+			"debug_log_max_size": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: descriptions["debug_log_max_size"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_DEBUG_LOG_MAX_SIZE", nil),
+			},
+			"debug_log_max_backups": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: descriptions["debug_log_max_backups"],
+				DefaultFunc: schema.EnvDefaultFunc("HSDP_DEBUG_LOG_MAX_BACKUPS", nil),
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"hsdp_iam_org":             resourceIAMOrg(),
-			"hsdp_iam_group":           resourceIAMGroup(),
-			"hsdp_iam_role":            resourceIAMRole(),
-			"hsdp_iam_proposition":     resourceIAMProposition(),
-			"hsdp_iam_application":     resourceIAMApplication(),
-			"hsdp_iam_user":            resourceIAMUser(),
-			"hsdp_iam_client":          resourceIAMClient(),
-			"hsdp_iam_service":         resourceIAMService(),
-			"hsdp_iam_mfa_policy":      resourceIAMMFAPolicy(),
-			"hsdp_iam_password_policy": resourceIAMPasswordPolicy(),
-			"hsdp_iam_email_template":  resourceIAMEmailTemplate(),
-			"hsdp_s3creds_policy":      resourceS3CredsPolicy(),
-			"hsdp_container_host":      resourceContainerHost(),
-			"hsdp_container_host_exec": resourceContainerHostExec(),
-			"hsdp_metrics_autoscaler":  resourceMetricsAutoscaler(),
-			"hsdp_cdr_org":             resourceCDROrg(),
-			"hsdp_cdr_subscription":    resourceCDRSubscription(),
-			"hsdp_dicom_store_config":  resourceDICOMStoreConfig(),
-			"hsdp_dicom_object_store":  resourceDICOMObjectStore(),
-			"hsdp_dicom_repository":    resourceDICOMRepository(),
-			"hsdp_pki_tenant":          resourcePKITenant(),
-			"hsdp_pki_cert":            resourcePKICert(),
-			"hsdp_stl_app":             resourceSTLApp(),
-			"hsdp_stl_config":          resourceSTLConfig(),
-			"hsdp_stl_custom_cert":     resourceSTLCustomCert(),
-			"hsdp_stl_sync":            resourceSTLSync(),
+			"hsdp_iam_org":              resourceIAMOrg(),
+			"hsdp_iam_group":            resourceIAMGroup(),
+			"hsdp_iam_group_membership": resourceIAMGroupMembership(),
+			"hsdp_iam_role":             resourceIAMRole(),
+			"hsdp_iam_proposition":      resourceIAMProposition(),
+			"hsdp_iam_application":      resourceIAMApplication(),
+			"hsdp_iam_user":             resourceIAMUser(),
+			"hsdp_iam_user_batch":       resourceIAMUserBatch(),
+			"hsdp_iam_client":           resourceIAMClient(),
+			"hsdp_iam_service":          resourceIAMService(),
+			"hsdp_iam_mfa_policy":       resourceIAMMFAPolicy(),
+			"hsdp_iam_password_policy":  resourceIAMPasswordPolicy(),
+			"hsdp_iam_email_template":   resourceIAMEmailTemplate(),
+			"hsdp_s3creds_policy":       resourceS3CredsPolicy(),
+			"hsdp_container_host":       resourceContainerHost(),
+			"hsdp_container_host_exec":  resourceContainerHostExec(),
+			"hsdp_metrics_autoscaler":   resourceMetricsAutoscaler(),
+			"hsdp_cdr_org":              resourceCDROrg(),
+			"hsdp_cdr_subscription":     resourceCDRSubscription(),
+			"hsdp_dicom_store_config":   resourceDICOMStoreConfig(),
+			"hsdp_dicom_object_store":   resourceDICOMObjectStore(),
+			"hsdp_dicom_repository":     resourceDICOMRepository(),
+			"hsdp_pki_tenant":           resourcePKITenant(),
+			"hsdp_pki_cert":             resourcePKICert(),
+			"hsdp_stl_app":              resourceSTLApp(),
+			"hsdp_stl_config":           resourceSTLConfig(),
+			"hsdp_stl_custom_cert":      resourceSTLCustomCert(),
+			"hsdp_stl_sync":             resourceSTLSync(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"hsdp_iam_introspect":              dataSourceIAMIntrospect(),
 			"hsdp_iam_user":                    dataSourceUser(),
+			"hsdp_iam_users":                   dataSourceIAMUsers(),
+			"hsdp_iam_role":                    dataSourceIAMRole(),
+			"hsdp_iam_group":                   dataSourceIAMGroup(),
 			"hsdp_iam_service":                 dataSourceService(),
 			"hsdp_iam_permissions":             dataSourceIAMPermissions(),
+			"hsdp_iam_effective_permissions":   dataSourceIAMEffectivePermissions(),
 			"hsdp_iam_org":                     dataSourceIAMOrg(),
+			"hsdp_iam_org_tree":                dataSourceIAMOrgTree(),
 			"hsdp_iam_proposition":             dataSourceIAMProposition(),
 			"hsdp_iam_application":             dataSourceIAMApplication(),
+			"hsdp_iam_email_templates":         dataSourceIAMEmailTemplates(),
+			"hsdp_iam_mfa_policies":            dataSourceIAMMFAPolicies(),
+			"hsdp_iam_password_policy":         dataSourceIAMPasswordPolicy(),
 			"hsdp_s3creds_access":              dataSourceS3CredsAccess(),
 			"hsdp_s3creds_policy":              dataSourceS3CredsPolicy(),
 			"hsdp_config":                      dataSourceConfig(),
@@ -212,35 +356,66 @@ func Provider(build string) *schema.Provider {
 	}
 }
 
+// providerDefaults holds the defaults of arguments which can also be set in a
+// profile. They are applied after the profile lookup instead of through the
+// schema, otherwise a schema default would always win over the profile
+var providerDefaults = map[string]interface{}{
+	"cartel_no_tls":         false,
+	"cartel_skip_verify":    true,
+	"insecure_skip_verify":  false,
+	"retry_max":             3,
+	"retry_wait_min":        1,
+	"retry_wait_max":        30,
+	"debug_log_max_size":    100,
+	"debug_log_max_backups": 3,
+}
+
 var descriptions map[string]string
 
 func init() {
 	descriptions = map[string]string{
-		"region":              "The HSDP region to configure for",
-		"environment":         "The HSDP environment to configure for",
-		"iam_url":             "The HSDP IAM instance URL",
-		"idm_url":             "The HSDP IDM instance URL",
-		"s3creds_url":         "The HSDP S3 Credentials instance URL",
-		"oauth2_client_id":    "The OAuth2 client id",
-		"oauth2_password":     "The OAuth2 password",
-		"service_id":          "The service ID to use as Organization Admin",
-		"service_private_key": "The private key of the service ID",
-		"org_id":              "The (top level) Organization ID - UUID",
-		"org_admin_username":  "The username of the Organization Admin",
-		"org_admin_password":  "The password of the Organization Admin",
-		"shared_key":          "The shared key",
-		"secret_key":          "The secret key",
-		"debug":               "Enable debugging output",
-		"debug_log":           "The log file to write debugging output to",
-		"cartel_host":         "The Cartel host",
-		"cartel_token":        "The Cartel token key",
-		"cartel_secret":       "The Cartel secret key",
-		"cartel_no_tls":       "Disable TLS for Cartel",
-		"cartel_skip_verify":  "Skip certificate verification",
-		"retry_max":           "Maximum number of retries for API requests",
-		"uaa_username":        "The username of the Cloudfoundry account to use",
-		"uaa_password":        "The password of the Cloudfoundry account to use",
-		"uaa_url":             "The URL of the UAA server",
+		"profile":               "The named profile to load from the credentials file",
+		"credentials_file":      "The credentials file to load profiles from, defaults to ~/.hsdp/credentials",
+		"region":                "The HSDP region to configure for",
+		"environment":           "The HSDP environment to configure for",
+		"iam_url":               "The HSDP IAM instance URL",
+		"idm_url":               "The HSDP IDM instance URL",
+		"s3creds_url":           "The HSDP S3 Credentials instance URL",
+		"console_url":           "The HSDP Console API URL",
+		"pki_url":               "The HSDP PKI API URL",
+		"stl_url":               "The HSDP STL GraphQL API URL",
+		"oauth2_client_id":      "The OAuth2 client id",
+		"oauth2_password":       "The OAuth2 password",
+		"service_id":            "The service ID to use as Organization Admin",
+		"service_private_key":   "The private key of the service ID",
+		"org_id":                "The (top level) Organization ID - UUID",
+		"org_admin_username":    "The username of the Organization Admin",
+		"org_admin_password":    "The password of the Organization Admin",
+		"shared_key":            "The shared key",
+		"secret_key":            "The secret key",
+		"debug":                 "Enable debugging output",
+		"debug_log":             "The log file to write debugging output to",
+		"debug_log_max_size":    "The size in megabytes after which the debug log is rotated, 0 disables rotation",
+		"debug_log_max_backups": "The number of rotated debug logs to keep",
+		"cartel_host":           "The Cartel host",
+		"cartel_token":          "The Cartel token key",
+		"cartel_secret":         "The Cartel secret key",
+		"cartel_no_tls":         "Disable TLS for Cartel",
+		"cartel_skip_verify":    "Skip certificate verification",
+		"ca_bundle":             "PEM encoded CA certificates to trust in addition to the system roots",
+		"client_cert":           "PEM encoded client certificate for mutual TLS",
+		"client_key":            "PEM encoded private key of the client certificate",
+		"http_proxy":            "Proxy URL for all HTTP(S) requests and container host SSH connections",
+		"no_proxy":              "Comma separated list of hosts which should not be proxied",
+		"insecure_skip_verify":  "Skip certificate verification for all HSDP services",
+		"retry_max":             "Maximum number of retries for API requests. Defaults to 3, set to 0 to disable retries",
+		"retry_wait_min":        "Minimum time in seconds to wait between retries",
+		"retry_wait_max":        "Maximum time in seconds to wait between retries",
+		"retry_status_codes":    "HTTP status codes of API responses which should be retried",
+		"rate_limit":            "Requests per second and in-flight request limits for an HSDP service",
+		"uaa_username":          "The username of the Cloudfoundry account to use",
+		"uaa_password":          "The password of the Cloudfoundry account to use",
+		"uaa_url":               "The URL of the UAA server",
 	}
 }
 
@@ -250,47 +425,85 @@ func providerConfigure(build string) schema.ConfigureContextFunc {
 
 		config := &Config{}
 
+		p, err := loadProfile(d.Get("credentials_file").(string), d.Get("profile").(string))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		if diags := p.validate(d); diags.HasError() {
+			return nil, diags
+		}
+
 		config.BuildVersion = build
-		config.Region = d.Get("region").(string)
-		config.Environment = d.Get("environment").(string)
-		config.IAMURL = d.Get("iam_url").(string)
-		config.IDMURL = d.Get("idm_url").(string)
-		config.OAuth2ClientID = d.Get("oauth2_client_id").(string)
-		config.OAuth2Secret = d.Get("oauth2_password").(string)
-		config.RootOrgID = d.Get("org_id").(string)
-		config.ServiceID = d.Get("service_id").(string)
-		config.ServicePrivateKey = d.Get("service_private_key").(string)
-		config.OrgAdminUsername = d.Get("org_admin_username").(string)
-		config.OrgAdminPassword = d.Get("org_admin_password").(string)
-		config.SharedKey = d.Get("shared_key").(string)
-		config.SecretKey = d.Get("secret_key").(string)
-		config.DebugLog = d.Get("debug_log").(string)
-		config.S3CredsURL = d.Get("s3creds_url").(string)
-		config.CartelHost = d.Get("cartel_host").(string)
-		config.CartelToken = d.Get("cartel_token").(string)
-		config.CartelSecret = d.Get("cartel_secret").(string)
-		config.CartelNoTLS = d.Get("cartel_no_tls").(bool)
-		config.CartelSkipVerify = d.Get("cartel_skip_verify").(bool)
-		config.RetryMax = d.Get("retry_max").(int)
-		config.UAAUsername = d.Get("uaa_username").(string)
-		config.UAAPassword = d.Get("uaa_password").(string)
-		config.UAAURL = d.Get("uaa_url").(string)
+		config.Region = p.getString(d, "region")
+		config.Environment = p.getString(d, "environment")
+		config.IAMURL = p.getString(d, "iam_url")
+		config.IDMURL = p.getString(d, "idm_url")
+		config.OAuth2ClientID = p.getString(d, "oauth2_client_id")
+		config.OAuth2Secret = p.getString(d, "oauth2_password")
+		config.RootOrgID = p.getString(d, "org_id")
+		config.ServiceID = p.getString(d, "service_id")
+		config.ServicePrivateKey = p.getString(d, "service_private_key")
+		config.OrgAdminUsername = p.getString(d, "org_admin_username")
+		config.OrgAdminPassword = p.getString(d, "org_admin_password")
+		config.SharedKey = p.getString(d, "shared_key")
+		config.SecretKey = p.getString(d, "secret_key")
+		config.DebugLog = p.getString(d, "debug_log")
+		config.S3CredsURL = p.getString(d, "s3creds_url")
+		config.ConsoleURL = p.getString(d, "console_url")
+		config.PKIURL = p.getString(d, "pki_url")
+		config.STLURL = p.getString(d, "stl_url")
+		config.CartelHost = p.getString(d, "cartel_host")
+		config.CartelToken = p.getString(d, "cartel_token")
+		config.CartelSecret = p.getString(d, "cartel_secret")
+		config.CartelNoTLS = p.getBool(d, "cartel_no_tls")
+		config.CABundle = p.getString(d, "ca_bundle")
+		config.CartelSkipVerify = p.getBool(d, "cartel_skip_verify")
+		config.ClientCert = p.getString(d, "client_cert")
+		config.ClientKey = p.getString(d, "client_key")
+		config.HTTPProxy = p.getString(d, "http_proxy")
+		config.NoProxy = p.getString(d, "no_proxy")
+		config.InsecureSkipVerify = p.getBool(d, "insecure_skip_verify")
+		config.RetryMax = p.getInt(d, "retry_max")
+		config.RetryWaitMin = time.Duration(p.getInt(d, "retry_wait_min")) * time.Second
+		config.RetryWaitMax = time.Duration(p.getInt(d, "retry_wait_max")) * time.Second
+		config.RetryStatusCodes = expandIntList(d.Get("retry_status_codes").(*schema.Set).List())
+		if len(config.RetryStatusCodes) == 0 {
+			config.RetryStatusCodes = p.getIntList("retry_status_codes")
+		}
+		config.RateLimits, err = expandRateLimits(d.Get("rate_limit").(*schema.Set).List())
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		config.UAAUsername = p.getString(d, "uaa_username")
+		config.UAAPassword = p.getString(d, "uaa_password")
+		config.UAAURL = p.getString(d, "uaa_url")
 		config.TimeZone = "UTC"
 
-		config.setupIAMClient()
-		config.setupS3CredsClient()
-		config.setupCartelClient()
-		config.setupConsoleClient()
-		config.setupPKIClient()
-		config.setupSTLClient()
+		if err := config.setupTransport(); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		if config.CABundle != "" && config.CartelToken != "" && config.CartelSkipVerify && !config.InsecureSkipVerify {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       "ca_bundle is not used for Cartel",
+				Detail:        "Cartel certificates are not verified while cartel_skip_verify is true, which is the default. Set cartel_skip_verify = false to verify them against ca_bundle",
+				AttributePath: cty.Path{cty.GetAttrStep{Name: "cartel_skip_verify"}},
+			})
+		}
+
+		// Service clients are set up lazily, see Config
 
 		if config.DebugLog != "" {
-			debugFile, err := os.OpenFile(config.DebugLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			maxSize := int64(p.getInt(d, "debug_log_max_size")) * 1024 * 1024
+			logger, err := newDebugLogger(config.DebugLog, maxSize, p.getInt(d, "debug_log_max_backups"))
 			if err != nil {
-				config.debugFile = nil
-			} else {
-				config.debugFile = debugFile
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "debug log disabled",
+					Detail:   fmt.Sprintf("opening %s: %v", config.DebugLog, err),
+				})
 			}
+			config.debugLogger = logger
 		}
 
 		ma, err := jsonformat.NewMarshaller(false, "", "", jsonformat.STU3)
@@ -22,6 +22,25 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+// TestProviderCredentialsResources validates the resources which only support
+// updates of their credentials block
+func TestProviderCredentialsResources(t *testing.T) {
+	p := Provider("v0.0.0")
+	for _, name := range []string{
+		"hsdp_iam_application",
+		"hsdp_iam_proposition",
+		"hsdp_iam_group_membership",
+	} {
+		r, ok := p.ResourcesMap[name]
+		if !ok {
+			t.Fatalf("resource %s is not registered", name)
+		}
+		if err := r.InternalValidate(nil, true); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
+
 func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider("v0.0.0")
 }
@@ -0,0 +1,204 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitServices are the services a rate_limit block can apply to
+var rateLimitServices = []string{"iam", "cartel", "console", "cdr", "dicom", "pki", "stl", "s3creds"}
+
+// rateLimit is the request budget of a single HSDP service. Zero values mean unlimited
+type rateLimit struct {
+	RequestsPerSecond float64
+	MaxInFlight       int
+}
+
+// serviceLimiter enforces a rateLimit with a token bucket and a semaphore
+type serviceLimiter struct {
+	limit rateLimit
+
+	sync.Mutex
+	tokens float64
+	last   time.Time
+
+	inFlight chan struct{}
+}
+
+func newServiceLimiter(limit rateLimit) *serviceLimiter {
+	l := &serviceLimiter{limit: limit, tokens: bucketSize(limit), last: time.Now()}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// bucketSize allows bursts of one second worth of requests, but at least one
+func bucketSize(limit rateLimit) float64 {
+	if limit.RequestsPerSecond < 1 {
+		return 1
+	}
+	return limit.RequestsPerSecond
+}
+
+// reserve takes a token from the bucket and returns how long to wait before it may be used
+func (l *serviceLimiter) reserve(now time.Time) time.Duration {
+	if l.limit.RequestsPerSecond <= 0 {
+		return 0
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.RequestsPerSecond
+	if max := bucketSize(l.limit); l.tokens > max {
+		l.tokens = max
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit.RequestsPerSecond * float64(time.Second))
+}
+
+// acquire blocks until a request may be sent. The returned release function
+// must be called once the request completed
+func (l *serviceLimiter) acquire(ctx context.Context) (time.Duration, func(), error) {
+	start := time.Now()
+	release := func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+			release = func() { <-l.inFlight }
+		case <-ctx.Done():
+			return time.Since(start), release, ctx.Err()
+		}
+	}
+	if wait := l.reserve(time.Now()); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return time.Since(start), func() {}, ctx.Err()
+		}
+	}
+	return time.Since(start), release, nil
+}
+
+// rateLimitTransport delays requests to services which are over their budget
+type rateLimitTransport struct {
+	next     http.RoundTripper
+	limiters map[string]*serviceLimiter
+	service  func(u *url.URL) string
+	logger   *debugLogger
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service := t.service(req.URL)
+	limiter, ok := t.limiters[service]
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	delay, release, err := limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if delay >= time.Millisecond {
+		t.logger.Log(debugLogEntry{
+			Type:       "rate_limit",
+			Service:    service,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			DurationMS: delay.Milliseconds(),
+			Message: fmt.Sprintf("request delayed %s (requests_per_second=%g, max_in_flight=%d)",
+				delay.Round(time.Millisecond), limiter.limit.RequestsPerSecond, limiter.limit.MaxInFlight),
+		})
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	// The request stays in flight until its response has been read
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// rateLimiters returns the limiters of the configured services, shared by all clients
+func (c *Config) rateLimiters() map[string]*serviceLimiter {
+	c.rateLimitersOnce.Do(func() {
+		c.limiters = make(map[string]*serviceLimiter)
+		for service, limit := range c.RateLimits {
+			c.limiters[service] = newServiceLimiter(limit)
+		}
+	})
+	return c.limiters
+}
+
+// serviceFor returns the HSDP service serving u. Explicitly configured URLs
+// take precedence over the host name heuristics of serviceName. STL is served
+// from the Console host, so a configured URL only matches below its path
+func (c *Config) serviceFor(u *url.URL) string {
+	configured := []struct {
+		url     string
+		service string
+	}{
+		{c.IAMURL, "iam"},
+		{c.IDMURL, "iam"},
+		{c.s3CredsURL(), "s3creds"},
+		{c.stlURL(), "stl"},
+		{c.UAAURL, "console"},
+		{c.ConsoleURL, "console"},
+		{c.PKIURL, "pki"},
+	}
+	for _, e := range configured {
+		if e.url == "" {
+			continue
+		}
+		base, err := url.Parse(e.url)
+		if err == nil && strings.EqualFold(base.Host, u.Host) &&
+			strings.HasPrefix(u.Path, strings.TrimRight(base.Path, "/")) {
+			return e.service
+		}
+	}
+	if c.CartelHost != "" && strings.EqualFold(strings.Split(u.Host, ":")[0], strings.Split(c.CartelHost, ":")[0]) {
+		return "cartel"
+	}
+	return serviceName(u.Host)
+}
+
+// expandRateLimits converts the rate_limit blocks of the provider
+func expandRateLimits(blocks []interface{}) (map[string]rateLimit, error) {
+	limits := make(map[string]rateLimit)
+	for _, b := range blocks {
+		block := b.(map[string]interface{})
+		service := block["service"].(string)
+		if _, ok := limits[service]; ok {
+			return nil, fmt.Errorf("duplicate rate_limit for service %q", service)
+		}
+		limits[service] = rateLimit{
+			RequestsPerSecond: block["requests_per_second"].(float64),
+			MaxInFlight:       block["max_in_flight"].(int),
+		}
+	}
+	return limits, nil
+}
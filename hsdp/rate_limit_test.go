@@ -0,0 +1,111 @@
+package hsdp
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceLimiterReserve(t *testing.T) {
+	l := newServiceLimiter(rateLimit{RequestsPerSecond: 2})
+	now := l.last
+
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now))
+	assert.Equal(t, time.Second, l.reserve(now))
+	// Two tokens are added back after a second
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now.Add(time.Second)))
+}
+
+func TestServiceLimiterMaxInFlight(t *testing.T) {
+	l := newServiceLimiter(rateLimit{MaxInFlight: 1})
+
+	_, release, err := l.acquire(context.Background())
+	if !assert.Nil(t, err) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = l.acquire(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	release()
+	_, release, err = l.acquire(context.Background())
+	assert.Nil(t, err)
+	release()
+}
+
+func TestExpandRateLimits(t *testing.T) {
+	limits, err := expandRateLimits([]interface{}{
+		map[string]interface{}{"service": "iam", "requests_per_second": 5.0, "max_in_flight": 4},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, rateLimit{RequestsPerSecond: 5, MaxInFlight: 4}, limits["iam"])
+
+	_, err = expandRateLimits([]interface{}{
+		map[string]interface{}{"service": "iam", "requests_per_second": 5.0, "max_in_flight": 0},
+		map[string]interface{}{"service": "iam", "requests_per_second": 1.0, "max_in_flight": 0},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestServiceFor(t *testing.T) {
+	c := &Config{CartelHost: "cartel.example.com"}
+	c.IAMURL = "https://iam.example.com"
+
+	assert.Equal(t, "iam", c.serviceFor(mustParseURL(t, "https://iam.example.com/authorize/identity/Group")))
+	assert.Equal(t, "cartel", c.serviceFor(mustParseURL(t, "https://cartel.example.com:443/v3/api/get_all_instances")))
+	assert.Equal(t, "s3creds", c.serviceFor(mustParseURL(t, "https://s3creds-client-test.us-east.philips-healthsuite.com/core/credentials/Policy")))
+
+	// Only a region is set, STL and Console share the regional Console host
+	c = &Config{}
+	c.Region = "us-east"
+	assert.Equal(t, "console", c.serviceFor(mustParseURL(t, "https://console.na1.hsdp.io/v3/metrics/app/autoscalers")))
+	assert.Equal(t, "stl", c.serviceFor(mustParseURL(t, "https://console.na1.hsdp.io/api/stl/user/v1/graphql")))
+}
+
+func TestRateLimitTransportRegionConsole(t *testing.T) {
+	c := &Config{}
+	c.Region = "us-east"
+	c.RateLimits = map[string]rateLimit{"console": {MaxInFlight: 1}}
+	transport := &rateLimitTransport{
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}")), Request: req}, nil
+		}),
+		limiters: c.rateLimiters(),
+		service:  c.serviceFor,
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://console.na1.hsdp.io/v3/metrics", nil)
+	resp, err := transport.RoundTrip(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// The Console request holds the only in-flight slot until its body is closed
+	assert.Equal(t, 1, len(c.rateLimiters()["console"].inFlight))
+	_ = resp.Body.Close()
+	assert.Equal(t, 0, len(c.rateLimiters()["console"].inFlight))
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", rawURL, err)
+	}
+	return u
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
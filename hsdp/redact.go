@@ -0,0 +1,46 @@
+package hsdp
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields lists the JSON and form field names which hold secrets in
+// HSDP API traffic, provider arguments and resource attributes
+var sensitiveFields = []string{
+	"password", "oldPassword", "newPassword", "oauth2_password", "org_admin_password", "uaa_password",
+	"privateKey", "private_key", "service_private_key", "privateKeyPem", "private_key_pem",
+	"secret", "secretKey", "secret_key", "client_secret", "cartel_secret",
+	"token", "cartel_token", "access_token", "accessToken", "refresh_token", "refreshToken",
+	"id_token", "idToken", "sessionToken", "session_token", "accessKey", "access_key",
+	"assertion",
+}
+
+var (
+	headerPattern = regexp.MustCompile(`(?im)^((?:proxy-)?authorization|cookie|set-cookie|api-key|x-api-key|signature|hsdp-api-signature)\s*:.*$`)
+	pemPattern    = regexp.MustCompile(`(?s)-----BEGIN ([A-Z0-9 ]+)-----.*?-----END [A-Z0-9 ]+-----`)
+	jsonPattern   *regexp.Regexp
+	formPattern   *regexp.Regexp
+)
+
+func init() {
+	quoted := make([]string, len(sensitiveFields))
+	for i, f := range sensitiveFields {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	names := strings.Join(quoted, "|")
+	jsonPattern = regexp.MustCompile(`(?i)"(` + names + `)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	formPattern = regexp.MustCompile(`(?i)\b(` + names + `)=[^&\s"]*`)
+}
+
+// redact removes credentials from s: authorization and cookie headers, PEM
+// blocks and the values of known sensitive fields in JSON and form encoded payloads
+func redact(s string) string {
+	s = headerPattern.ReplaceAllString(s, "$1: "+redacted)
+	s = pemPattern.ReplaceAllString(s, "-----BEGIN $1-----"+redacted+"-----END $1-----")
+	s = jsonPattern.ReplaceAllString(s, `"$1"$2"`+redacted+`"`)
+	s = formPattern.ReplaceAllString(s, "$1="+redacted)
+	return s
+}
@@ -7,12 +7,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	jsonpatch "github.com/herkyl/patchwerk"
 	"github.com/philips-software/go-hsdp-api/cdr/helper/fhir/stu3"
+	"net/http"
 )
 
 func resourceCDROrg() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importCDROrgContext,
 		},
 
 		CreateContext: resourceCDROrgCreate,
@@ -21,6 +22,7 @@ func resourceCDROrg() *schema.Resource {
 		DeleteContext: resourceCDROrgDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"fhir_store": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -43,6 +45,16 @@ func resourceCDROrg() *schema.Resource {
 	}
 }
 
+// importCDROrgContext imports an organization by fhir_store,org_id
+func importCDROrgContext(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	result, err := importStateWithFields("fhir_store")(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	_ = d.Set("org_id", d.Id())
+	return result, nil
+}
+
 func resourceCDROrgCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
@@ -51,7 +63,7 @@ func resourceCDROrgCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	endpoint := d.Get("fhir_store").(string)
 	orgID := d.Get("org_id").(string)
 
-	client, err := config.getFHIRClientFromEndpoint(endpoint)
+	client, err := config.getFHIRClientFromEndpoint(endpoint, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -81,9 +93,9 @@ func resourceCDROrgCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		return resourceCDROrgUpdate(ctx, d, m)
 	}
 	// Do initial boarding
-	onboardedOrg, _, err = client.TenantSTU3.Onboard(org)
+	onboardedOrg, resp, err := client.TenantSTU3.Onboard(org)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(onboardedOrg.Id.Value)
 	return diags
@@ -97,12 +109,16 @@ func resourceCDROrgRead(ctx context.Context, d *schema.ResourceData, m interface
 	endpoint := d.Get("fhir_store").(string)
 	orgID := d.Get("org_id").(string)
 
-	client, err := config.getFHIRClientFromEndpoint(endpoint)
+	client, err := config.getFHIRClientFromEndpoint(endpoint, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
 	org, resp, err := client.TenantSTU3.GetOrganizationByID(orgID)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return diags
+	}
 	if err != nil || resp == nil {
 		if resp == nil {
 			diags = append(diags, diag.Diagnostic{
@@ -120,7 +136,7 @@ func resourceCDROrgRead(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	_ = d.Set("name", org.Name.Value)
 	if org.PartOf != nil {
-		_ = d.Set("part_of", org.PartOf.GetOrganizationId())
+		_ = d.Set("part_of", org.PartOf.GetOrganizationId().GetValue())
 	}
 	return diags
 }
@@ -132,15 +148,15 @@ func resourceCDROrgUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 	endpoint := d.Get("fhir_store").(string)
 	id := d.Id()
 
-	client, err := config.getFHIRClientFromEndpoint(endpoint)
+	client, err := config.getFHIRClientFromEndpoint(endpoint, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
 
-	org, _, err := client.TenantSTU3.GetOrganizationByID(id)
+	org, resp, err := client.TenantSTU3.GetOrganizationByID(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	jsonOrg, err := config.ma.MarshalResource(org)
 	if err != nil {
@@ -176,9 +192,9 @@ func resourceCDROrgUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	_, _, err = client.OperationsSTU3.Patch("Organization/"+id, patch)
+	_, resp, err = client.OperationsSTU3.Patch("Organization/"+id, patch)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	return diags
@@ -0,0 +1,126 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testCDROrgConfig(f *fakeHSDP, name, partOf string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_cdr_org" "test" {
+  fhir_store = "%s/store/fhir/fake-root-org"
+  org_id     = "fake-child-org"
+  name       = %q
+  part_of    = %q
+}
+`, f.URL, name, partOf)
+}
+
+// testCheckCDROrg checks the name and parent of the onboarded organization in the fake
+func testCheckCDROrg(f *fakeHSDP, name, partOf string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		org := f.get("FHIR/fake-root-org/Organization", "fake-child-org")
+		if org == nil {
+			return fmt.Errorf("fake-child-org is not onboarded")
+		}
+		if org["name"] != name {
+			return fmt.Errorf("expected name %q, got %v", name, org["name"])
+		}
+		reference := ""
+		if parent, ok := org["partOf"].(map[string]interface{}); ok {
+			reference = fmt.Sprint(parent["reference"])
+		}
+		if reference != partOf {
+			return fmt.Errorf("expected partOf %q, got %q", partOf, reference)
+		}
+		return nil
+	}
+}
+
+func TestResourceCDROrg(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			return nil // CDR does not support offboarding organizations
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testCDROrgConfig(f, "Child", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_cdr_org.test", "id", "fake-child-org"),
+					testCheckCDROrg(f, "Child", ""),
+				),
+			},
+			{
+				// Changes are sent as a JSON patch of the onboarded organization
+				Config: testCDROrgConfig(f, "Renamed child", "fake-root-org"),
+				Check:  testCheckCDROrg(f, "Renamed child", "Organization/fake-root-org"),
+			},
+			{
+				ResourceName:            "hsdp_cdr_org.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testImportID("hsdp_cdr_org.test", f.URL+"/store/fhir/fake-root-org"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials"},
+			},
+		},
+	})
+}
+
+func TestResourceCDROrgAdoptOnboarded(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.put("FHIR/fake-root-org/Organization", fakeObject{
+		"resourceType": "Organization",
+		"id":           "fake-child-org",
+		"meta":         fakeObject{"versionId": "1"},
+		"name":         "Onboarded elsewhere",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				// An organization which is already onboarded is patched instead
+				Config: testCDROrgConfig(f, "Child", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckCDROrg(f, "Child", ""),
+					testCheckFakeCount(f, "FHIR/fake-root-org/Organization", 1),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCDROrgDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testCDROrgConfig(f, "Child", ""),
+			},
+			{
+				// The organization was renamed in the CDR
+				PreConfig: func() {
+					f.update("FHIR/fake-root-org/Organization", "fake-child-org", fakeObject{"name": "Renamed elsewhere"})
+				},
+				Config: testCDROrgConfig(f, "Child", ""),
+				Check:  testCheckCDROrg(f, "Child", ""),
+			},
+			{
+				// The store was reset, the organization is onboarded again
+				PreConfig: func() {
+					f.remove("FHIR/fake-root-org/Organization", "fake-child-org")
+				},
+				Config: testCDROrgConfig(f, "Child", ""),
+				Check:  testCheckCDROrg(f, "Child", ""),
+			},
+		},
+	})
+}
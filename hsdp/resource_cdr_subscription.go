@@ -8,13 +8,15 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	jsonpatch "github.com/herkyl/patchwerk"
 	"github.com/philips-software/go-hsdp-api/cdr/helper/fhir/stu3"
+	"net/http"
+	"strings"
 	"time"
 )
 
 func resourceCDRSubscription() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithFields("fhir_store"),
 		},
 
 		CreateContext: resourceCDRSubscriptionCreate,
@@ -23,6 +25,7 @@ func resourceCDRSubscription() *schema.Resource {
 		DeleteContext: resourceCDRSubscriptionDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"fhir_store": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -78,7 +81,7 @@ func resourceCDRSubscriptionCreate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	client, err := config.getFHIRClientFromEndpoint(fhirStore)
+	client, err := config.getFHIRClientFromEndpoint(fhirStore, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -115,12 +118,16 @@ func resourceCDRSubscriptionRead(ctx context.Context, d *schema.ResourceData, m
 
 	fhirStore := d.Get("fhir_store").(string)
 
-	client, err := config.getFHIRClientFromEndpoint(fhirStore)
+	client, err := config.getFHIRClientFromEndpoint(fhirStore, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("subscription read: %w", err))
 	}
 	defer client.Close()
 	contained, resp, err := client.OperationsSTU3.Get("Subscription/" + d.Id())
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return diags
+	}
 	if err != nil || resp == nil {
 		if resp == nil {
 			diags = append(diags, diag.Diagnostic{
@@ -140,7 +147,14 @@ func resourceCDRSubscriptionRead(ctx context.Context, d *schema.ResourceData, m
 	_ = d.Set("endpoint", sub.Channel.Endpoint.Value)
 	_ = d.Set("reason", sub.Reason.Value)
 	_ = d.Set("criteria", sub.Criteria.Value)
-	_ = d.Set("status", sub.Status.Value)
+	_ = d.Set("status", strings.ToLower(sub.Status.Value.String()))
+	if sub.End != nil {
+		// Keep the configured notation of the same instant
+		end := time.Unix(0, sub.End.ValueUs*1000).UTC()
+		if current, err := time.Parse(time.RFC3339, d.Get("end").(string)); err != nil || !current.Equal(end) {
+			_ = d.Set("end", end.Format(time.RFC3339))
+		}
+	}
 	_ = d.Set("delete_endpoint", stu3.DeleteEndpointValue()(sub))
 	headers := make([]string, 0)
 	for _, h := range sub.Channel.Header {
@@ -157,7 +171,7 @@ func resourceCDRSubscriptionUpdate(ctx context.Context, d *schema.ResourceData,
 	fhirStore := d.Get("fhir_store").(string)
 	id := d.Id()
 
-	client, err := config.getFHIRClientFromEndpoint(fhirStore)
+	client, err := config.getFHIRClientFromEndpoint(fhirStore, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -218,9 +232,9 @@ func resourceCDRSubscriptionUpdate(ctx context.Context, d *schema.ResourceData,
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("subscription update: %w", err))
 	}
-	_, _, err = client.OperationsSTU3.Patch("Subscription/"+id, patch)
+	_, resp, err := client.OperationsSTU3.Patch("Subscription/"+id, patch)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("subscription update: %w", err))
+		return diagFromResponse(fmt.Errorf("subscription update: %w", err), resp)
 	}
 
 	return diags
@@ -233,15 +247,15 @@ func resourceCDRSubscriptionDelete(ctx context.Context, d *schema.ResourceData,
 	fhirStore := d.Get("fhir_store").(string)
 	id := d.Id()
 
-	client, err := config.getFHIRClientFromEndpoint(fhirStore)
+	client, err := config.getFHIRClientFromEndpoint(fhirStore, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
 
-	ok, _, err := client.OperationsSTU3.Delete("Subscription/" + id)
+	ok, resp, err := client.OperationsSTU3.Delete("Subscription/" + id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrDeleteSubscriptionFailed)
@@ -0,0 +1,85 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testCDRSubscriptionConfig(f *fakeHSDP, reason, headers string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_cdr_subscription" "test" {
+  fhir_store      = "%s/store/fhir/fake-root-org"
+  criteria        = "Patient"
+  reason          = %q
+  endpoint        = "https://webhook.example.com/patient"
+  delete_endpoint = "https://webhook.example.com/patient_deleted"
+  headers         = %s
+  end             = "2099-12-31T23:59:59Z"
+}
+`, f.URL, reason, headers)
+}
+
+func TestResourceCDRSubscription(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "FHIR/fake-root-org/Subscription", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testCDRSubscriptionConfig(f, "Notify on patients", `["Authorization: Basic cm9uOnN3YW5zb24="]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "FHIR/fake-root-org/Subscription", "hsdp_cdr_subscription.test"),
+					testSaveID("hsdp_cdr_subscription.test", &id),
+					resource.TestCheckResourceAttr("hsdp_cdr_subscription.test", "headers.#", "1"),
+				),
+			},
+			{
+				// The subscription is patched in place
+				Config: testCDRSubscriptionConfig(f, "Notify on all patients", `["Authorization: Basic cm9uOnN3YW5zb24=", "X-Test: yes"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("hsdp_cdr_subscription.test", "id", &id),
+					resource.TestCheckResourceAttr("hsdp_cdr_subscription.test", "reason", "Notify on all patients"),
+					resource.TestCheckResourceAttr("hsdp_cdr_subscription.test", "headers.#", "2"),
+					resource.TestCheckResourceAttr("hsdp_cdr_subscription.test", "delete_endpoint", "https://webhook.example.com/patient_deleted"),
+				),
+			},
+			{
+				Config:   testCDRSubscriptionConfig(f, "Notify on all patients", `["Authorization: Basic cm9uOnN3YW5zb24=", "X-Test: yes"]`),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:            "hsdp_cdr_subscription.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testImportID("hsdp_cdr_subscription.test", f.URL+"/store/fhir/fake-root-org"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials"},
+			},
+		},
+	})
+}
+
+func TestResourceCDRSubscriptionDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testCDRSubscriptionConfig(f, "Notify on patients", `[]`),
+				Check:  testSaveID("hsdp_cdr_subscription.test", &id),
+			},
+			{
+				PreConfig: func() {
+					f.remove("FHIR/fake-root-org/Subscription", id)
+				},
+				Config: testCDRSubscriptionConfig(f, "Notify on patients", `[]`),
+				Check:  testCheckFakeRecreated(f, "FHIR/fake-root-org/Subscription", "hsdp_cdr_subscription.test", &id),
+			},
+		},
+	})
+}
@@ -379,7 +379,7 @@ func resourceContainerHostCreate(ctx context.Context, d *schema.ResourceData, m
 		Server: privateIP,
 		Port:   "22",
 		Key:    privateKey,
-		Proxy:  http.ProxyFromEnvironment,
+		Proxy:  config.proxyFunc(),
 		Bastion: easyssh.DefaultConfig{
 			User:   user,
 			Server: bastionHost,
@@ -388,8 +388,10 @@ func resourceContainerHostCreate(ctx context.Context, d *schema.ResourceData, m
 		},
 	}
 
+	debug := config.debugFor("hsdp_container_host", d.Id())
+
 	// Create files
-	if err := copyFiles(ssh, config, createFiles); err != nil {
+	if err := copyFiles(ssh, debug, createFiles); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Warning,
 			Summary:  "failed to copy all files",
@@ -403,7 +405,7 @@ func resourceContainerHostCreate(ctx context.Context, d *schema.ResourceData, m
 		if err != nil {
 			return append(diags, diag.FromErr(fmt.Errorf("command [%s]: %w", commands[i], err))...)
 		} else {
-			_, _ = config.Debug("command: %s\ndone: %t\nstdout:\n%s\nstderr:\n%s\n", commands[i], done, stdout, stderr)
+			debug("command: %s\ndone: %t\nstdout:\n%s\nstderr:\n%s\n", commands[i], done, stdout, stderr)
 		}
 	}
 	readDiags := resourceContainerHostRead(ctx, d, m)
@@ -423,28 +425,28 @@ func findInstanceByName(client *cartel.Client, name string) *cartel.InstanceDeta
 	return nil
 }
 
-func copyFiles(ssh *easyssh.MakeConfig, config *Config, createFiles []provisionFile) error {
+func copyFiles(ssh *easyssh.MakeConfig, debug debugFunc, createFiles []provisionFile) error {
 	for _, f := range createFiles {
 		if f.Source != "" {
 			src, srcErr := os.Open(f.Source)
 			if srcErr != nil {
-				_, _ = config.Debug("Failed to open source file %s: %v\n", f.Source, srcErr)
+				debug("Failed to open source file %s: %v\n", f.Source, srcErr)
 				return srcErr
 			}
 			srcStat, statErr := src.Stat()
 			if statErr != nil {
-				_, _ = config.Debug("Failed to stat source file %s: %v\n", f.Source, statErr)
+				debug("Failed to stat source file %s: %v\n", f.Source, statErr)
 				_ = src.Close()
 				return statErr
 			}
 			_ = ssh.WriteFile(src, srcStat.Size(), f.Destination)
-			_, _ = config.Debug("Copied %s to remote file %s:%s: %d bytes\n", f.Source, ssh.Server, f.Destination, srcStat.Size())
+			debug("Copied %s to remote file %s:%s: %d bytes\n", f.Source, ssh.Server, f.Destination, srcStat.Size())
 			_ = src.Close()
 		} else {
 			buffer := bytes.NewBufferString(f.Content)
 			// Should we fail the complete provision on errors here?
 			_ = ssh.WriteFile(buffer, int64(buffer.Len()), f.Destination)
-			_, _ = config.Debug("Created remote file %s:%s: %d bytes\n", ssh.Server, f.Destination, len(f.Content))
+			debug("Created remote file %s:%s: %d bytes\n", ssh.Server, f.Destination, len(f.Content))
 		}
 	}
 	return nil
@@ -534,9 +536,9 @@ func resourceContainerHostUpdate(_ context.Context, d *schema.ResourceData, m in
 	}
 
 	tagName := d.Get("name").(string)
-	ch, _, err := client.GetDetails(tagName)
+	ch, resp, err := client.GetDetails(tagName)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ch.InstanceID != d.Id() {
 		return diag.FromErr(ErrInstanceIDMismatch)
@@ -546,9 +548,9 @@ func resourceContainerHostUpdate(_ context.Context, d *schema.ResourceData, m in
 		o, n := d.GetChange("tags")
 		change := generateTagChange(o, n)
 		log.Printf("[o:%v] [n:%v] [c:%v]\n", o, n, change)
-		_, _, err := client.AddTags([]string{tagName}, change)
+		_, resp, err := client.AddTags([]string{tagName}, change)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 	}
 	if d.HasChange("user_groups") {
@@ -560,17 +562,17 @@ func resourceContainerHostUpdate(_ context.Context, d *schema.ResourceData, m in
 
 		// Additions
 		if len(toAdd) > 0 {
-			_, _, err := client.AddUserGroups([]string{tagName}, toAdd)
+			_, resp, err := client.AddUserGroups([]string{tagName}, toAdd)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 		}
 
 		// Removals
 		if len(toRemove) > 0 {
-			_, _, err := client.RemoveUserGroups([]string{tagName}, toRemove)
+			_, resp, err := client.RemoveUserGroups([]string{tagName}, toRemove)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 		}
 	}
@@ -584,25 +586,25 @@ func resourceContainerHostUpdate(_ context.Context, d *schema.ResourceData, m in
 
 		// Additions
 		if len(toAdd) > 0 {
-			_, _, err := client.AddSecurityGroups([]string{tagName}, toAdd)
+			_, resp, err := client.AddSecurityGroups([]string{tagName}, toAdd)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 		}
 
 		// Removals
 		if len(toRemove) > 0 {
-			_, _, err := client.RemoveSecurityGroups([]string{tagName}, toRemove)
+			_, resp, err := client.RemoveSecurityGroups([]string{tagName}, toRemove)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 		}
 	}
 	if d.HasChange("protect") {
 		protect := d.Get("protect").(bool)
-		_, _, err := client.SetProtection(tagName, protect)
+		_, resp, err := client.SetProtection(tagName, protect)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 	}
 	return diags
@@ -652,9 +654,9 @@ func resourceContainerHostRead(_ context.Context, d *schema.ResourceData, m inte
 		d.SetId("")
 		return diags
 	}
-	ch, _, err := client.GetDetails(tagName)
+	ch, resp, err := client.GetDetails(tagName)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ch.InstanceID != d.Id() {
 		return diag.FromErr(ErrInstanceIDMismatch)
@@ -695,16 +697,16 @@ func resourceContainerHostDelete(_ context.Context, d *schema.ResourceData, m in
 	}
 
 	tagName := d.Get("name").(string)
-	ch, _, err := client.GetDetails(tagName)
+	ch, resp, err := client.GetDetails(tagName)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ch.InstanceID != d.Id() {
 		return diag.FromErr(ErrInstanceIDMismatch)
 	}
-	_, _, err = client.Destroy(tagName)
+	_, resp, err = client.Destroy(tagName)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId("")
 	return diags
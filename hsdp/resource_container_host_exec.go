@@ -7,7 +7,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/loafoe/easyssh-proxy/v2"
 	"math/rand"
-	"net/http"
 	"time"
 )
 
@@ -125,7 +124,7 @@ func resourceContainerHostExecCreate(_ context.Context, d *schema.ResourceData,
 		Server: privateIP,
 		Port:   "22",
 		Key:    privateKey,
-		Proxy:  http.ProxyFromEnvironment,
+		Proxy:  config.proxyFunc(),
 		Bastion: easyssh.DefaultConfig{
 			User:   user,
 			Server: bastionHost,
@@ -134,8 +133,11 @@ func resourceContainerHostExecCreate(_ context.Context, d *schema.ResourceData,
 		},
 	}
 
+	id := fmt.Sprintf("%d", rand.Int())
+	debug := config.debugFor("hsdp_container_host_exec", id)
+
 	// Provision files
-	if err := copyFiles(ssh, config, createFiles); err != nil {
+	if err := copyFiles(ssh, debug, createFiles); err != nil {
 		return diag.FromErr(fmt.Errorf("copying files to remote: %w", err))
 	}
 
@@ -145,11 +147,11 @@ func resourceContainerHostExecCreate(_ context.Context, d *schema.ResourceData,
 		if err != nil {
 			return append(diags, diag.FromErr(fmt.Errorf("command [%s]: %w", commands[i], err))...)
 		} else {
-			_, _ = config.Debug("command: %s\ndone: %t\nstdout:\n%s\nstderr:\n%s\n", commands[i], done, stdout, stderr)
+			debug("command: %s\ndone: %t\nstdout:\n%s\nstderr:\n%s\n", commands[i], done, stdout, stderr)
 		}
 	}
 
-	d.SetId(fmt.Sprintf("%d", rand.Int()))
+	d.SetId(id)
 	return diags
 }
 
@@ -0,0 +1,143 @@
+package hsdp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testContainerHostExecConfig(f *fakeHSDP, trigger string, commands ...string) string {
+	return f.providerConfig(fmt.Sprintf("http_proxy = %q", f.URL)) + fmt.Sprintf(`
+resource "hsdp_container_host_exec" "test" {
+  host         = "10.0.0.5"
+  bastion_host = "bastion.fake.example.com"
+  user         = "core"
+  private_key  = %q
+  commands     = [%s]
+
+  triggers = {
+    config = %q
+  }
+
+  file {
+    content     = "listen 8080\n"
+    destination = "/home/core/app.conf"
+  }
+}
+`, f.sshKey(), `"`+strings.Join(commands, `", "`)+`"`, trigger)
+}
+
+// testCheckSSHCommands checks the commands run on host so far, in order
+func testCheckSSHCommands(f *fakeHSDP, host string, commands ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		h := f.sshHost(host)
+		if h == nil {
+			return fmt.Errorf("no SSH connection to %s", host)
+		}
+		if strings.Join(h.commands, "\n") != strings.Join(commands, "\n") {
+			return fmt.Errorf("expected commands %q on %s, got %q", commands, host, h.commands)
+		}
+		return nil
+	}
+}
+
+// testCheckSSHFile checks a file copied to host and that it went through the bastion
+func testCheckSSHFile(f *fakeHSDP, host, destination, content string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		h := f.sshHost(host)
+		if h == nil {
+			return fmt.Errorf("no SSH connection to %s", host)
+		}
+		if h.via != "bastion.fake.example.com" {
+			return fmt.Errorf("expected %s to be reached through the bastion, got %q", host, h.via)
+		}
+		if got, ok := h.files[destination]; !ok || got != content {
+			return fmt.Errorf("expected %s:%s to contain %q, got %q", host, destination, content, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceContainerHostExec(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testContainerHostExecConfig(f, "v1", "docker pull nginx", "docker restart app"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSSHCommands(f, "10.0.0.5", "docker pull nginx", "docker restart app"),
+					testCheckSSHFile(f, "10.0.0.5", "/home/core/app.conf", "listen 8080\n"),
+				),
+			},
+			{
+				// Nothing changed, nothing runs
+				Config: testContainerHostExecConfig(f, "v1", "docker pull nginx", "docker restart app"),
+				Check:  testCheckSSHCommands(f, "10.0.0.5", "docker pull nginx", "docker restart app"),
+			},
+			{
+				// A changed trigger runs the commands again
+				Config: testContainerHostExecConfig(f, "v2", "docker pull nginx", "docker restart app"),
+				Check: testCheckSSHCommands(f, "10.0.0.5",
+					"docker pull nginx", "docker restart app",
+					"docker pull nginx", "docker restart app"),
+			},
+		},
+	})
+}
+
+func TestResourceContainerHostExecFileSource(t *testing.T) {
+	f := newFakeHSDP(t)
+	source := filepath.Join(t.TempDir(), "app.env")
+	if err := ioutil.WriteFile(source, []byte("PORT=8080\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.providerConfig(fmt.Sprintf("http_proxy = %q", f.URL)) + fmt.Sprintf(`
+resource "hsdp_container_host_exec" "test" {
+  host         = "10.0.0.5"
+  bastion_host = "bastion.fake.example.com"
+  user         = "core"
+  private_key  = %q
+
+  file {
+    source      = %q
+    destination = "/home/core/app.env"
+  }
+}
+`, f.sshKey(), source),
+				Check: testCheckSSHFile(f, "10.0.0.5", "/home/core/app.env", "PORT=8080\n"),
+			},
+		},
+	})
+}
+
+func TestResourceContainerHostExecFailure(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testContainerHostExecConfig(f, "v1", "true", "false", "echo unreachable"),
+				ExpectError: regexp.MustCompile(`command \[false\]: Process exited with status 1`),
+			},
+			{
+				// The commands after the failed one did not run
+				Config: testContainerHostExecConfig(f, "v2", "true"),
+				Check:  testCheckSSHCommands(f, "10.0.0.5", "true", "false", "true"),
+			},
+		},
+	})
+}
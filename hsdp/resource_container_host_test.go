@@ -0,0 +1,111 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testContainerHostConfig(f *fakeHSDP) string {
+	return f.providerConfig() + `
+resource "hsdp_container_host" "test" {
+  name          = "test-host.dev"
+  instance_type = "m5.large"
+  tags = {
+    "billing" = ""
+  }
+}
+`
+}
+
+func testCheckContainerHostDestroy(f *fakeHSDP) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		f.Lock()
+		defer f.Unlock()
+		if len(f.instances) > 0 {
+			return fmt.Errorf("%d instances still exist", len(f.instances))
+		}
+		return nil
+	}
+}
+
+func TestResourceContainerHost(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckContainerHostDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testContainerHostConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_container_host.test", "name", "test-host.dev"),
+					resource.TestCheckResourceAttrSet("hsdp_container_host.test", "private_ip"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceContainerHostCreateRecovery(t *testing.T) {
+	f := newFakeHSDP(t)
+	// The instance is created but the gateway times out, Create should find it
+	f.failAfterNext(http.MethodPost, "/v3/api/create", http.StatusGatewayTimeout, 1)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckContainerHostDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testContainerHostConfig(f),
+				Check: func(s *terraform.State) error {
+					f.Lock()
+					defer f.Unlock()
+					if len(f.instances) != 1 {
+						return fmt.Errorf("expected 1 instance, found %d", len(f.instances))
+					}
+					return resource.TestCheckResourceAttr("hsdp_container_host.test",
+						"id", f.instances["test-host.dev"]["instance_id"].(string))(s)
+				},
+			},
+		},
+	})
+}
+
+func TestResourceContainerHostDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckContainerHostDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testContainerHostConfig(f),
+			},
+			{
+				// Destroyed outside of Terraform, Read reports a 400 and the host is recreated
+				PreConfig: func() {
+					f.Lock()
+					delete(f.instances, "test-host.dev")
+					f.Unlock()
+				},
+				Config: testContainerHostConfig(f),
+				Check:  resource.TestCheckResourceAttrSet("hsdp_container_host.test", "id"),
+			},
+			{
+				// Import looks up the name tag of the instance
+				ResourceName: "hsdp_container_host.test",
+				ImportState:  true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["name"] != "test-host.dev" {
+						return fmt.Errorf("expected test-host.dev to be imported")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -5,18 +5,21 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/dicom"
+	"net/http"
 )
 
 func resourceDICOMObjectStore() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithFields("config_url", "organization_id"),
 		},
 		CreateContext: resourceDICOMObjectStoreCreate,
 		ReadContext:   resourceDICOMObjectStoreRead,
+		UpdateContext: updateCredentialsOnly,
 		DeleteContext: resourceDICOMObjectStoreDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"config_url": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -42,19 +45,23 @@ func resourceDICOMObjectStore() *schema.Resource {
 						"endpoint": {
 							Type:     schema.TypeString,
 							Required: true,
+							ForceNew: true,
 						},
 						"bucket_name": {
 							Type:     schema.TypeString,
 							Required: true,
+							ForceNew: true,
 						},
 						"access_key": {
 							Type:      schema.TypeString,
 							Required:  true,
+							ForceNew:  true,
 							Sensitive: true,
 						},
 						"secret_key": {
 							Type:      schema.TypeString,
 							Required:  true,
+							ForceNew:  true,
 							Sensitive: true,
 						},
 					},
@@ -74,44 +81,59 @@ func resourceDICOMObjectStore() *schema.Resource {
 						"endpoint": {
 							Type:     schema.TypeString,
 							Required: true,
+							ForceNew: true,
 						},
 						"bucket_name": {
 							Type:      schema.TypeString,
 							Required:  true,
+							ForceNew:  true,
 							Sensitive: true,
 						},
 						"folder_path": {
 							Type:      schema.TypeString,
 							Required:  true,
+							ForceNew:  true,
+							Sensitive: true,
+						},
+						"product_key": {
+							Type:      schema.TypeString,
+							Required:  true,
+							ForceNew:  true,
 							Sensitive: true,
 						},
 						"service_account": {
 							Type:     schema.TypeSet,
 							Optional: true,
+							ForceNew: true,
 							MaxItems: 1,
 							Elem: &schema.Resource{
 								Schema: map[string]*schema.Schema{
 									"name": {
 										Type:     schema.TypeString,
 										Required: true,
+										ForceNew: true,
 									},
 									"service_id": {
 										Type:      schema.TypeString,
 										Required:  true,
+										ForceNew:  true,
 										Sensitive: true,
 									},
 									"private_key": {
 										Type:      schema.TypeString,
 										Required:  true,
+										ForceNew:  true,
 										Sensitive: true,
 									},
 									"access_token_endpoint": {
 										Type:     schema.TypeString,
 										Optional: true,
+										ForceNew: true,
 									},
 									"token_endpoint": {
 										Type:     schema.TypeString,
 										Optional: true,
+										ForceNew: true,
 									},
 								},
 							},
@@ -128,16 +150,16 @@ func resourceDICOMObjectStoreDelete(_ context.Context, d *schema.ResourceData, m
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
-	_, _, err = client.Config.DeleteObjectStore(dicom.ObjectStore{ID: d.Id()}, &dicom.QueryOptions{
+	_, resp, err := client.Config.DeleteObjectStore(dicom.ObjectStore{ID: d.Id()}, &dicom.QueryOptions{
 		OrganizationID: &orgID,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId("")
 	return diags
@@ -148,17 +170,21 @@ func resourceDICOMObjectStoreRead(_ context.Context, d *schema.ResourceData, m i
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
 
-	store, _, err := client.Config.GetObjectStore(d.Id(), &dicom.QueryOptions{
+	store, resp, err := client.Config.GetObjectStore(d.Id(), &dicom.QueryOptions{
 		OrganizationID: &orgID,
 	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("description", store.Description)
 	_ = d.Set("access_type", store.AccessType)
@@ -174,6 +200,7 @@ func resourceDICOMObjectStoreRead(_ context.Context, d *schema.ResourceData, m i
 	}
 	if store.CredServiceAccess != nil {
 		credsSettings := make(map[string]interface{})
+		credsSettings["endpoint"] = store.CredServiceAccess.Endpoint
 		credsSettings["folder_path"] = store.CredServiceAccess.FolderPath
 		credsSettings["bucket_name"] = store.CredServiceAccess.BucketName
 		credsSettings["product_key"] = store.CredServiceAccess.ProductKey
@@ -199,7 +226,7 @@ func resourceDICOMObjectStoreRead(_ context.Context, d *schema.ResourceData, m i
 func resourceDICOMObjectStoreCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	orgID := d.Get("organization_id").(string)
 	if err != nil {
 		return diag.FromErr(err)
@@ -228,6 +255,7 @@ func resourceDICOMObjectStoreCreate(ctx context.Context, d *schema.ResourceData,
 		var aVi []interface{}
 		for _, vi := range vL {
 			mVi := vi.(map[string]interface{})
+			credsAccess.Endpoint = mVi["endpoint"].(string)
 			credsAccess.BucketName = mVi["bucket_name"].(string)
 			credsAccess.FolderPath = mVi["folder_path"].(string)
 			credsAccess.ProductKey = mVi["product_key"].(string)
@@ -244,11 +272,11 @@ func resourceDICOMObjectStoreCreate(ctx context.Context, d *schema.ResourceData,
 		store.CredServiceAccess = credsAccess
 		store.AccessType = "s3Creds"
 	}
-	created, _, err := client.Config.CreateObjectStore(store, &dicom.QueryOptions{
+	created, resp, err := client.Config.CreateObjectStore(store, &dicom.QueryOptions{
 		OrganizationID: &orgID,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(created.ID)
 	return resourceDICOMObjectStoreRead(ctx, d, m)
@@ -0,0 +1,141 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testDICOMObjectStoreStaticConfig(f *fakeHSDP, bucket string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_dicom_object_store" "test" {
+  config_url      = "%s/dicom-config"
+  organization_id = "fake-root-org"
+  description     = "Test store"
+
+  static_access {
+    endpoint    = "https://s3.example.com"
+    bucket_name = %q
+    access_key  = "access"
+    secret_key  = "secret"
+  }
+}
+`, f.URL, bucket)
+}
+
+func testDICOMObjectStoreS3CredsConfig(f *fakeHSDP) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_dicom_object_store" "test" {
+  config_url      = "%s/dicom-config"
+  organization_id = "fake-root-org"
+  description     = "Test store"
+
+  s3creds_access {
+    endpoint    = "https://s3creds.example.com"
+    product_key = "fake-product-key"
+    bucket_name = "test-bucket"
+    folder_path = "/dicom"
+
+    service_account {
+      name                  = "dicom"
+      service_id            = "dicom@fake-application.example.com"
+      private_key           = "fake-private-key"
+      access_token_endpoint = "https://iam.example.com/oauth2/access_token"
+      token_endpoint        = "https://iam.example.com/authorize/oauth2/token"
+    }
+  }
+}
+`, f.URL)
+}
+
+func TestResourceDICOMObjectStoreStatic(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "DICOM/objectStores", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMObjectStoreStaticConfig(f, "test-bucket"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "DICOM/objectStores", "hsdp_dicom_object_store.test"),
+					testSaveID("hsdp_dicom_object_store.test", &id),
+					resource.TestCheckResourceAttr("hsdp_dicom_object_store.test", "access_type", "static"),
+				),
+			},
+			{
+				// Object stores can not be updated, they are replaced
+				Config: testDICOMObjectStoreStaticConfig(f, "other-bucket"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeRecreated(f, "DICOM/objectStores", "hsdp_dicom_object_store.test", &id),
+					testCheckFakeCount(f, "DICOM/objectStores", 1),
+				),
+			},
+			{
+				ResourceName:            "hsdp_dicom_object_store.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testImportID("hsdp_dicom_object_store.test", f.URL+"/dicom-config", "fake-root-org"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials"},
+			},
+		},
+	})
+}
+
+func TestResourceDICOMObjectStoreS3Creds(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "DICOM/objectStores", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMObjectStoreS3CredsConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "DICOM/objectStores", "hsdp_dicom_object_store.test"),
+					resource.TestCheckResourceAttr("hsdp_dicom_object_store.test", "access_type", "s3Creds"),
+					func(s *terraform.State) error {
+						id := s.RootModule().Resources["hsdp_dicom_object_store.test"].Primary.ID
+						if store := f.get("DICOM/objectStores", id); store != nil {
+							access, _ := store["credServiceAccess"].(map[string]interface{})
+							if access["endPoint"] != "https://s3creds.example.com" || access["productKey"] != "fake-product-key" {
+								return fmt.Errorf("unexpected S3 credentials access: %v", access)
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// Everything read back matches the configuration
+				Config:   testDICOMObjectStoreS3CredsConfig(f),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestResourceDICOMObjectStoreDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMObjectStoreStaticConfig(f, "test-bucket"),
+				Check:  testSaveID("hsdp_dicom_object_store.test", &id),
+			},
+			{
+				PreConfig: func() {
+					f.remove("DICOM/objectStores", id)
+				},
+				Config: testDICOMObjectStoreStaticConfig(f, "test-bucket"),
+				Check:  testCheckFakeRecreated(f, "DICOM/objectStores", "hsdp_dicom_object_store.test", &id),
+			},
+		},
+	})
+}
@@ -5,18 +5,21 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/dicom"
+	"net/http"
 )
 
 func resourceDICOMRepository() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithFields("config_url", "organization_id"),
 		},
 		CreateContext: resourceDICOMRepositoryCreate,
 		ReadContext:   resourceDICOMRepositoryRead,
+		UpdateContext: updateCredentialsOnly,
 		DeleteContext: resourceDICOMRepositoryDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"config_url": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -41,14 +44,14 @@ func resourceDICOMRepositoryDelete(_ context.Context, d *schema.ResourceData, m
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
-	_, _, err = client.Config.DeleteRepository(dicom.Repository{ID: d.Id()}, &dicom.QueryOptions{OrganizationID: &orgID})
+	_, resp, err := client.Config.DeleteRepository(dicom.Repository{ID: d.Id()}, &dicom.QueryOptions{OrganizationID: &orgID})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId("")
 	return diags
@@ -59,14 +62,18 @@ func resourceDICOMRepositoryRead(_ context.Context, d *schema.ResourceData, m in
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	defer client.Close()
-	repo, _, err := client.Config.GetRepository(d.Id(), &dicom.QueryOptions{OrganizationID: &orgID})
+	repo, resp, err := client.Config.GetRepository(d.Id(), &dicom.QueryOptions{OrganizationID: &orgID})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("organization_id", repo.OrganizationID)
 	_ = d.Set("object_store_id", repo.ActiveObjectStoreID)
@@ -77,7 +84,7 @@ func resourceDICOMRepositoryCreate(ctx context.Context, d *schema.ResourceData,
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -86,9 +93,9 @@ func resourceDICOMRepositoryCreate(ctx context.Context, d *schema.ResourceData,
 		OrganizationID:      d.Get("organization_id").(string),
 		ActiveObjectStoreID: d.Get("object_store_id").(string),
 	}
-	created, _, err := client.Config.CreateRepository(repo, &dicom.QueryOptions{OrganizationID: &orgID})
+	created, resp, err := client.Config.CreateRepository(repo, &dicom.QueryOptions{OrganizationID: &orgID})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(created.ID)
 	return resourceDICOMRepositoryRead(ctx, d, m)
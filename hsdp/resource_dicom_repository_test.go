@@ -0,0 +1,79 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testDICOMRepositoryConfig(f *fakeHSDP) string {
+	return testDICOMObjectStoreStaticConfig(f, "test-bucket") + fmt.Sprintf(`
+resource "hsdp_dicom_repository" "test" {
+  config_url      = "%s/dicom-config"
+  organization_id = "fake-root-org"
+  object_store_id = hsdp_dicom_object_store.test.id
+}
+`, f.URL)
+}
+
+func TestResourceDICOMRepository(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckFakeCount(f, "DICOM/dicomRepositories", 0),
+			testCheckFakeCount(f, "DICOM/objectStores", 0),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMRepositoryConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "DICOM/dicomRepositories", "hsdp_dicom_repository.test"),
+					resource.TestCheckResourceAttrPair("hsdp_dicom_repository.test", "object_store_id", "hsdp_dicom_object_store.test", "id"),
+					func(s *terraform.State) error {
+						id := s.RootModule().Resources["hsdp_dicom_repository.test"].Primary.ID
+						if org := f.get("DICOM/dicomRepositories", id)["organizationId"]; org != "fake-root-org" {
+							return fmt.Errorf("repository belongs to %v", org)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:            "hsdp_dicom_repository.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testImportID("hsdp_dicom_repository.test", f.URL+"/dicom-config", "fake-root-org"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials"},
+			},
+		},
+	})
+}
+
+func TestResourceDICOMRepositoryDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMRepositoryConfig(f),
+				Check:  testSaveID("hsdp_dicom_repository.test", &id),
+			},
+			{
+				PreConfig: func() {
+					f.remove("DICOM/dicomRepositories", id)
+				},
+				Config: testDICOMRepositoryConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeRecreated(f, "DICOM/dicomRepositories", "hsdp_dicom_repository.test", &id),
+					testCheckFakeCount(f, "DICOM/objectStores", 1),
+				),
+			},
+		},
+	})
+}
@@ -7,12 +7,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/dicom"
+	"strings"
 )
 
 func resourceDICOMStoreConfig() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importDICOMStoreConfigContext,
 		},
 		CreateContext: resourceDICOMStoreConfigCreate,
 		ReadContext:   resourceDICOMStoreConfigRead,
@@ -20,6 +21,7 @@ func resourceDICOMStoreConfig() *schema.Resource {
 		DeleteContext: resourceDICOMStoreConfigDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"config_url": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -84,6 +86,19 @@ func resourceDICOMStoreConfig() *schema.Resource {
 	}
 }
 
+// importDICOMStoreConfigContext imports the config of an organization by
+// config_url,organization_id
+func importDICOMStoreConfigContext(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ",")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("%w: %q, expected config_url,organization_id", ErrInvalidImportID, d.Id())
+	}
+	_ = d.Set("config_url", parts[0])
+	_ = d.Set("organization_id", parts[1])
+	d.SetId(fmt.Sprintf("%x", md5.Sum([]byte(parts[0]))))
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceDICOMStoreConfigDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	d.SetId("")
@@ -95,7 +110,7 @@ func resourceDICOMStoreConfigUpdate(_ context.Context, d *schema.ResourceData, m
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -114,11 +129,11 @@ func resourceDICOMStoreConfigUpdate(_ context.Context, d *schema.ResourceData, m
 			if !cdrService.Valid() {
 				return diag.FromErr(fmt.Errorf("cdr_service_account is not valid"))
 			}
-			configured, _, err := client.Config.SetCDRServiceAccount(cdrService, &dicom.QueryOptions{
+			configured, resp, err := client.Config.SetCDRServiceAccount(cdrService, &dicom.QueryOptions{
 				OrganizationID: &orgID,
 			})
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 			cdrSettings := make(map[string]interface{})
 			cdrSettings["service_id"] = configured.ServiceID
@@ -140,15 +155,14 @@ func resourceDICOMStoreConfigUpdate(_ context.Context, d *schema.ResourceData, m
 			if !fhirStore.Valid() {
 				return diag.FromErr(fmt.Errorf("fhir_store is not valid"))
 			}
-			configured, _, err := client.Config.SetFHIRStore(fhirStore, &dicom.QueryOptions{
+			configured, resp, err := client.Config.SetFHIRStore(fhirStore, &dicom.QueryOptions{
 				OrganizationID: &orgID,
 			})
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp)
 			}
 			fhirSettings := make(map[string]interface{})
 			fhirSettings["mpi_endpoint"] = configured.MPIEndpoint
-			fhirSettings["id"] = configured.ID
 			s := &schema.Set{F: resourceMetricsThresholdHash}
 			s.Add(fhirSettings)
 			_ = d.Set("fhir_store", s)
@@ -162,7 +176,7 @@ func resourceDICOMStoreConfigRead(_ context.Context, d *schema.ResourceData, m i
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -188,11 +202,13 @@ func resourceDICOMStoreConfigRead(_ context.Context, d *schema.ResourceData, m i
 	if err == nil && fhirConfigured != nil {
 		fhirSettings := make(map[string]interface{})
 		fhirSettings["mpi_endpoint"] = fhirConfigured.MPIEndpoint
-		fhirSettings["id"] = fhirConfigured.ID
 		s := &schema.Set{F: resourceMetricsThresholdHash}
 		s.Add(fhirSettings)
 		_ = d.Set("fhir_store", s)
 	}
+	_ = d.Set("qido_url", client.GetQIDOURL())
+	_ = d.Set("wado_url", client.GetWADOURL())
+	_ = d.Set("stow_url", client.GetSTOWURL())
 	return diags
 }
 
@@ -201,7 +217,7 @@ func resourceDICOMStoreConfigCreate(_ context.Context, d *schema.ResourceData, m
 	config := m.(*Config)
 	configURL := d.Get("config_url").(string)
 	orgID := d.Get("organization_id").(string)
-	client, err := config.getDICOMConfigClient(configURL)
+	client, err := config.getDICOMConfigClient(configURL, principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -220,11 +236,11 @@ func resourceDICOMStoreConfigCreate(_ context.Context, d *schema.ResourceData, m
 		if !cdrService.Valid() {
 			return diag.FromErr(fmt.Errorf("cdr_service_account is not valid"))
 		}
-		configured, _, err := client.Config.SetCDRServiceAccount(cdrService, &dicom.QueryOptions{
+		configured, resp, err := client.Config.SetCDRServiceAccount(cdrService, &dicom.QueryOptions{
 			OrganizationID: &orgID,
 		})
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		cdrSettings := make(map[string]interface{})
 		cdrSettings["service_id"] = configured.ServiceID
@@ -245,15 +261,14 @@ func resourceDICOMStoreConfigCreate(_ context.Context, d *schema.ResourceData, m
 		if !fhirStore.Valid() {
 			return diag.FromErr(fmt.Errorf("fhir_store is not valid"))
 		}
-		configured, _, err := client.Config.SetFHIRStore(fhirStore, &dicom.QueryOptions{
+		configured, resp, err := client.Config.SetFHIRStore(fhirStore, &dicom.QueryOptions{
 			OrganizationID: &orgID,
 		})
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		fhirSettings := make(map[string]interface{})
 		fhirSettings["mpi_endpoint"] = configured.MPIEndpoint
-		fhirSettings["id"] = configured.ID
 		s := &schema.Set{F: resourceMetricsThresholdHash}
 		s.Add(fhirSettings)
 		_ = d.Set("fhir_store", s)
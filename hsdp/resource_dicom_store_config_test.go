@@ -0,0 +1,95 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testDICOMStoreConfigConfig(f *fakeHSDP, serviceID string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_dicom_store_config" "test" {
+  config_url      = "%s/dicom-config"
+  organization_id = "fake-root-org"
+
+  cdr_service_account {
+    service_id  = %q
+    private_key = "fake-private-key"
+  }
+
+  fhir_store {
+    mpi_endpoint = "https://cdr.example.com/store/fhir/fake-root-org"
+  }
+}
+`, f.URL, serviceID)
+}
+
+// testCheckDICOMServiceAccount checks the CDR service account configured in the fake
+func testCheckDICOMServiceAccount(f *fakeHSDP, serviceID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		account := f.get("DICOM/cdrServiceAccount", "fake-root-org")
+		if account == nil || account["serviceId"] != serviceID {
+			return fmt.Errorf("expected CDR service account %s, got %v", serviceID, account)
+		}
+		return nil
+	}
+}
+
+func TestResourceDICOMStoreConfig(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMStoreConfigConfig(f, "cdr@fake-application.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckDICOMServiceAccount(f, "cdr@fake-application.example.com"),
+					resource.TestCheckResourceAttr("hsdp_dicom_store_config.test", "qido_url", f.URL+"/dicom-qido"),
+					resource.TestCheckResourceAttr("hsdp_dicom_store_config.test", "wado_url", f.URL+"/dicom-wado"),
+					resource.TestCheckResourceAttr("hsdp_dicom_store_config.test", "stow_url", f.URL+"/dicom-stow"),
+					func(s *terraform.State) error {
+						if store := f.get("DICOM/fhirStore", "fake-root-org"); store == nil {
+							return fmt.Errorf("FHIR store was not configured")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testDICOMStoreConfigConfig(f, "other@fake-application.example.com"),
+				Check:  testCheckDICOMServiceAccount(f, "other@fake-application.example.com"),
+			},
+			{
+				ResourceName:            "hsdp_dicom_store_config.test",
+				ImportState:             true,
+				ImportStateId:           f.URL + "/dicom-config,fake-root-org",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials"},
+			},
+		},
+	})
+}
+
+func TestResourceDICOMStoreConfigDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testDICOMStoreConfigConfig(f, "cdr@fake-application.example.com"),
+			},
+			{
+				// The service account was changed outside Terraform
+				PreConfig: func() {
+					f.update("DICOM/cdrServiceAccount", "fake-root-org", fakeObject{"serviceId": "someone@else.example.com"})
+				},
+				Config: testDICOMStoreConfigConfig(f, "cdr@fake-application.example.com"),
+				Check:  testCheckDICOMServiceAccount(f, "cdr@fake-application.example.com"),
+			},
+		},
+	})
+}
@@ -2,6 +2,7 @@ package hsdp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -17,9 +18,11 @@ func resourceIAMApplication() *schema.Resource {
 
 		CreateContext: resourceIAMApplicationCreate,
 		ReadContext:   resourceIAMApplicationRead,
+		UpdateContext: updateCredentialsOnly,
 		DeleteContext: resourceIAMApplicationDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -50,7 +53,7 @@ func resourceIAMApplicationCreate(_ context.Context, d *schema.ResourceData, m i
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -62,17 +65,26 @@ func resourceIAMApplicationCreate(_ context.Context, d *schema.ResourceData, m i
 	app.GlobalReferenceID = d.Get("global_reference_id").(string)
 
 	createdApp, resp, err := client.Applications.CreateApplication(app)
+	// CreateApplication drops the error when the create is refused
+	if err == nil && createdApp == nil {
+		err = ErrCreateApplicationFailed
+	}
 	if err != nil {
 		if resp == nil {
 			return diag.FromErr(err)
 		}
 		if resp.StatusCode != http.StatusConflict {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
-		createdApp, _, err = client.Applications.GetApplicationByName(app.Name)
+		// GetApplicationByName searches by ID, so look the name up within the proposition
+		apps, resp, err := client.Applications.GetApplications(&iam.GetApplicationsOptions{
+			Name:          &app.Name,
+			PropositionID: &app.PropositionID,
+		})
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
+		createdApp = apps[0]
 		if createdApp.Description != app.Description {
 			return diag.FromErr(fmt.Errorf("existing application found but description mismatch: '%s' != '%s'", createdApp.Description, app.Description))
 		}
@@ -97,7 +109,7 @@ func resourceIAMApplicationRead(_ context.Context, d *schema.ResourceData, m int
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -105,7 +117,8 @@ func resourceIAMApplicationRead(_ context.Context, d *schema.ResourceData, m int
 	id := d.Id()
 	app, resp, err := client.Applications.GetApplicationByID(id)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// An application which is gone is not found by the search behind GetApplicationByID
+		if errors.Is(err, iam.ErrNotFound) || (resp != nil && resp.StatusCode == http.StatusNotFound) {
 			d.SetId("")
 			return diags
 		}
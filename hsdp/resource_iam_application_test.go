@@ -0,0 +1,152 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMApplicationConfig(f *fakeHSDP, propositionID, description string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_application" "test" {
+  name                = "TESTAPPLICATION"
+  description         = %q
+  proposition_id      = %q
+  global_reference_id = "test-application-ref"
+}
+`, description, propositionID)
+}
+
+func testFakeProposition(f *fakeHSDP) string {
+	return f.put("Proposition", fakeObject{
+		"name":              "TESTPROPOSITION",
+		"description":       "Test proposition",
+		"organizationId":    "fake-root-org",
+		"globalReferenceId": "test-proposition-ref",
+	})
+}
+
+func TestResourceIAMApplication(t *testing.T) {
+	f := newFakeHSDP(t)
+	propositionID := testFakeProposition(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			return nil // IAM does not support deleting applications
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMApplicationConfig(f, propositionID, "Test application"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Application", "hsdp_iam_application.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_application.test", "proposition_id", propositionID),
+				),
+			},
+			{
+				ResourceName:      "hsdp_iam_application.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMApplicationAdoptExisting(t *testing.T) {
+	f := newFakeHSDP(t)
+	propositionID := testFakeProposition(f)
+	otherID := f.put("Proposition", fakeObject{"name": "OTHERPROPOSITION", "organizationId": "fake-root-org"})
+	// An application of the same name in another proposition is not it
+	f.put("Application", fakeObject{
+		"name":              "TESTAPPLICATION",
+		"description":       "Other application",
+		"propositionId":     otherID,
+		"globalReferenceId": "other-application-ref",
+	})
+	id := f.put("Application", fakeObject{
+		"name":              "TESTAPPLICATION",
+		"description":       "Test application",
+		"propositionId":     propositionID,
+		"globalReferenceId": "test-application-ref",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMApplicationConfig(f, propositionID, "Test application"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_application.test", "id", id),
+					testCheckFakeCount(f, "Application", 2),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMApplicationExistingMismatch(t *testing.T) {
+	f := newFakeHSDP(t)
+	propositionID := testFakeProposition(f)
+	f.put("Application", fakeObject{
+		"name":              "TESTAPPLICATION",
+		"description":       "Test application",
+		"propositionId":     propositionID,
+		"globalReferenceId": "someone-elses-ref",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testIAMApplicationConfig(f, propositionID, "Test application"),
+				ExpectError: regexp.MustCompile("existing application found but global_reference_id mismatch"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMApplicationDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	propositionID := testFakeProposition(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMApplicationConfig(f, propositionID, "Test application"),
+				Check:  testSaveID("hsdp_iam_application.test", &id),
+			},
+			{
+				// GetApplicationByID turns the empty search for a missing
+				// application into ErrNotFound, without a 404 to go by
+				PreConfig: func() {
+					f.remove("Application", id)
+				},
+				Config: testIAMApplicationConfig(f, propositionID, "Test application"),
+				Check:  testCheckFakeRecreated(f, "Application", "hsdp_iam_application.test", &id),
+			},
+		},
+	})
+}
+
+func TestResourceIAMApplicationRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	propositionID := testFakeProposition(f)
+	// The create reads the application back through the search
+	f.failNext(http.MethodGet, "/authorize/identity/Application", http.StatusBadGateway, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMApplicationConfig(f, propositionID, "Test application"),
+				Check:  testCheckFakeExists(f, "Application", "hsdp_iam_application.test"),
+			},
+		},
+	})
+}
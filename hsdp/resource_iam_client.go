@@ -2,13 +2,26 @@ package hsdp
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"crypto/rand"
+	"errors"
+	"math/big"
 	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/iam"
 )
 
+// clientPasswordClasses are the character classes a client password must contain
+var clientPasswordClasses = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!#$%&*+-=?@^_",
+}
+
+const clientPasswordLength = 16
+
 func resourceIAMClient() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
@@ -19,8 +32,10 @@ func resourceIAMClient() *schema.Resource {
 		ReadContext:   resourceIAMClientRead,
 		UpdateContext: resourceIAMClientUpdate,
 		DeleteContext: resourceIAMClientDelete,
+		CustomizeDiff: customizeClientPassword,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
 				ForceNew: true,
@@ -38,10 +53,21 @@ func resourceIAMClient() *schema.Resource {
 				DiffSuppressFunc: suppressCaseDiffs,
 			},
 			"password": &schema.Schema{
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
-				ForceNew:  true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"generate_password"},
+			},
+			"generate_password": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"rotation_triggers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 			"description": &schema.Schema{
 				Type:     schema.TypeString,
@@ -109,12 +135,62 @@ func resourceIAMClient() *schema.Resource {
 	}
 }
 
+// generateClientPassword returns a random password which meets the IAM client
+// password policy: 8-16 characters with upper and lower case, digits and specials
+func generateClientPassword() (string, error) {
+	var all string
+	for _, class := range clientPasswordClasses {
+		all += class
+	}
+	password := make([]byte, clientPasswordLength)
+	for i := range password {
+		chars := all
+		if i < len(clientPasswordClasses) {
+			chars = clientPasswordClasses[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = chars[n.Int64()]
+	}
+	// Shuffle so the required classes are not always up front
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// passwordChanges is implemented by both schema.ResourceData and schema.ResourceDiff
+type passwordChanges interface {
+	Get(key string) interface{}
+	HasChange(key string) bool
+}
+
+// passwordRotation reports whether a new password should be generated
+func passwordRotation(d passwordChanges) bool {
+	return d.Get("generate_password").(bool) && (d.HasChange("rotation_triggers") || d.HasChange("generate_password"))
+}
+
+// customizeClientPassword plans a new generated password when rotation_triggers change
+func customizeClientPassword(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !passwordRotation(d) {
+		return nil
+	}
+	return d.SetNewComputed("password")
+}
+
 func resourceIAMClientCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -126,7 +202,15 @@ func resourceIAMClientCreate(ctx context.Context, d *schema.ResourceData, m inte
 	cl.Type = d.Get("type").(string)
 	cl.GlobalReferenceID = d.Get("global_reference_id").(string)
 	cl.Password = d.Get("password").(string)
-	cl.Name = d.Get("name").(string)
+	if d.Get("generate_password").(bool) {
+		cl.Password, err = generateClientPassword()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if cl.Password == "" {
+		return diag.FromErr(ErrMissingClientPassword)
+	}
 	cl.RedirectionURIs = expandStringList(d.Get("redirection_uris").(*schema.Set).List())
 	cl.ResponseTypes = expandStringList(d.Get("response_types").(*schema.Set).List())
 	cl.ApplicationID = d.Get("application_id").(string)
@@ -136,9 +220,9 @@ func resourceIAMClientCreate(ctx context.Context, d *schema.ResourceData, m inte
 	cl.RefreshTokenLifetime = d.Get("refresh_token_lifetime").(int)
 	cl.AccessTokenLifetime = d.Get("access_token_lifetime").(int)
 
-	createdClient, _, err := client.Clients.CreateClient(cl)
+	createdClient, resp, err := client.Clients.CreateClient(cl)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(createdClient.ID)
 	_ = d.Set("password", cl.Password)
@@ -150,7 +234,7 @@ func resourceIAMClientRead(ctx context.Context, d *schema.ResourceData, m interf
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -158,7 +242,8 @@ func resourceIAMClientRead(ctx context.Context, d *schema.ResourceData, m interf
 	id := d.Id()
 	cl, resp, err := client.Clients.GetClientByID(id)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// A client which is gone is not found by the search behind GetClientByID
+		if errors.Is(err, iam.ErrEmptyResults) || (resp != nil && resp.StatusCode == http.StatusNotFound) {
 			d.SetId("")
 			return diags
 		}
@@ -188,7 +273,7 @@ func resourceIAMClientUpdate(ctx context.Context, d *schema.ResourceData, m inte
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -207,21 +292,32 @@ func resourceIAMClientUpdate(ctx context.Context, d *schema.ResourceData, m inte
 			_, nd := d.GetChange("default_scopes")
 			newDefaultScopes = expandStringList(nd.(*schema.Set).List())
 		}
-		_, _, err := client.Clients.UpdateScopes(cl, newScopes, newDefaultScopes)
+		_, resp, err := client.Clients.UpdateScopes(cl, newScopes, newDefaultScopes)
+		if err != nil {
+			return diagFromResponse(err, resp)
+		}
+	}
+	// Passwords are reset in place so client_id and redirection_uris stay stable
+	var newPassword string
+	if passwordRotation(d) {
+		newPassword, err = generateClientPassword()
 		if err != nil {
 			return diag.FromErr(err)
 		}
+	} else if d.HasChange("password") && !d.Get("generate_password").(bool) {
+		newPassword = d.Get("password").(string)
 	}
-	if d.HasChange("access_token_lifetime") ||
+	if newPassword != "" ||
+		d.HasChange("access_token_lifetime") ||
 		d.HasChange("refresh_token_lifetime") ||
 		d.HasChange("id_token_lifetime") ||
 		d.HasChange("consent_implied") ||
 		d.HasChange("global_reference_id") ||
 		d.HasChange("response_types") ||
 		d.HasChange("redirection_uris") {
-		cl, _, err := client.Clients.GetClientByID(d.Id())
+		cl, resp, err := client.Clients.GetClientByID(d.Id())
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		cl.RedirectionURIs = expandStringList(d.Get("redirection_uris").(*schema.Set).List())
 		cl.ResponseTypes = expandStringList(d.Get("response_types").(*schema.Set).List())
@@ -230,9 +326,13 @@ func resourceIAMClientUpdate(ctx context.Context, d *schema.ResourceData, m inte
 		cl.RefreshTokenLifetime = d.Get("refresh_token_lifetime").(int)
 		cl.IDTokenLifetime = d.Get("id_token_lifetime").(int)
 		cl.GlobalReferenceID = d.Get("global_reference_id").(string)
-		_, _, err = client.Clients.UpdateClient(*cl)
+		cl.Password = newPassword
+		_, resp, err = client.Clients.UpdateClient(*cl)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
+		}
+		if newPassword != "" {
+			_ = d.Set("password", newPassword)
 		}
 		return resourceIAMClientRead(ctx, d, m)
 	}
@@ -244,16 +344,16 @@ func resourceIAMClientDelete(ctx context.Context, d *schema.ResourceData, m inte
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	var cl iam.ApplicationClient
 	cl.ID = d.Id()
-	ok, _, err := client.Clients.DeleteClient(cl)
+	ok, resp, err := client.Clients.DeleteClient(cl)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrDeleteClientFailed)
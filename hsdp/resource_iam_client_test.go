@@ -0,0 +1,142 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMClientConfig(f *fakeHSDP, rotation string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_client" "test" {
+  type                = "Public"
+  name                = "testclient"
+  description         = "Test client"
+  client_id           = "testclient"
+  generate_password   = true
+  application_id      = "fake-application"
+  global_reference_id = "some-ref"
+  redirection_uris    = ["https://foo/bar"]
+  response_types      = ["code"]
+  scopes              = ["openid"]
+  default_scopes      = ["openid"]
+
+  rotation_triggers = {
+    rotation = %q
+  }
+}
+`, rotation)
+}
+
+// testCheckFakeClientPassword verifies the fake received the password in state
+// and that the client was not recreated
+func testCheckFakeClientPassword(f *fakeHSDP, id *string, password *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs := s.RootModule().Resources["hsdp_iam_client.test"]
+		if *id != "" && rs.Primary.ID != *id {
+			return fmt.Errorf("client was recreated: %s != %s", rs.Primary.ID, *id)
+		}
+		client := f.get("Client", rs.Primary.ID)
+		if client == nil {
+			return fmt.Errorf("client %s does not exist in fake", rs.Primary.ID)
+		}
+		if client["password"] != rs.Primary.Attributes["password"] {
+			return fmt.Errorf("password in state was not sent to IAM")
+		}
+		if rs.Primary.Attributes["password"] == *password {
+			return fmt.Errorf("password was not changed")
+		}
+		*id = rs.Primary.ID
+		*password = rs.Primary.Attributes["password"]
+		return nil
+	}
+}
+
+func TestResourceIAMClientPasswordRotation(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id, password string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMClientConfig(f, "2021-01"),
+				Check:  testCheckFakeClientPassword(f, &id, &password),
+			},
+			{
+				Config: testIAMClientConfig(f, "2021-02"),
+				Check:  testCheckFakeClientPassword(f, &id, &password),
+			},
+		},
+	})
+}
+
+func TestGenerateClientPassword(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		password, err := generateClientPassword()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Len(t, password, clientPasswordLength)
+		assert.True(t, strings.IndexFunc(password, unicode.IsUpper) >= 0)
+		assert.True(t, strings.IndexFunc(password, unicode.IsLower) >= 0)
+		assert.True(t, strings.IndexFunc(password, unicode.IsDigit) >= 0)
+		assert.True(t, strings.ContainsAny(password, clientPasswordClasses[3]))
+	}
+}
+
+func TestResourceIAMClientDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMClientConfig(f, "2021-01"),
+				Check:  testSaveID("hsdp_iam_client.test", &id),
+			},
+			{
+				// Clients are read through a search, which comes back empty
+				// rather than with a 404 once the client is deleted
+				PreConfig: func() {
+					f.remove("Client", id)
+				},
+				Config: testIAMClientConfig(f, "2021-01"),
+				Check:  testCheckFakeRecreated(f, "Client", "hsdp_iam_client.test", &id),
+			},
+			{
+				ResourceName:            "hsdp_iam_client.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password", "generate_password", "rotation_triggers"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMClientRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	// IAM rate limits the create and the search which reads the client back
+	f.failNext(http.MethodPost, "/authorize/identity/Client", http.StatusTooManyRequests, 1)
+	f.failNext(http.MethodGet, "/authorize/identity/Client", http.StatusTooManyRequests, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMClientConfig(f, "2021-01"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Client", "hsdp_iam_client.test"),
+					testCheckFakeCount(f, "Client", 1),
+				),
+			},
+		},
+	})
+}
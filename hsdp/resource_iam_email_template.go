@@ -3,6 +3,11 @@ package hsdp
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/iam"
@@ -11,14 +16,16 @@ import (
 func resourceIAMEmailTemplate() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importEmailTemplateContext,
 		},
 
 		CreateContext: resourceIAMEmailTemplateCreate,
 		ReadContext:   resourceIAMEmailTemplateRead,
+		UpdateContext: resourceIAMEmailTemplateUpdate,
 		DeleteContext: resourceIAMEmailTemplateDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"managing_organization": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -32,25 +39,21 @@ func resourceIAMEmailTemplate() *schema.Resource {
 			"from": &schema.Schema{
 				Type:             schema.TypeString,
 				Optional:         true,
-				ForceNew:         true,
 				DiffSuppressFunc: suppressDefault,
 			},
 			"format": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "HTML",
 			},
 			"subject": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "default",
 			},
 			"message": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 			"locale": &schema.Schema{
 				Type:             schema.TypeString,
@@ -61,7 +64,6 @@ func resourceIAMEmailTemplate() *schema.Resource {
 			"link": &schema.Schema{
 				Type:             schema.TypeString,
 				Optional:         true,
-				ForceNew:         true,
 				DiffSuppressFunc: suppressDefault,
 			},
 			"message_base64": &schema.Schema{
@@ -72,17 +74,11 @@ func resourceIAMEmailTemplate() *schema.Resource {
 	}
 }
 
-func resourceIAMEmailTemplateCreate(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	config := m.(*Config)
-
-	var diags diag.Diagnostics
-
-	client, err := config.IAMClient()
-	if err != nil {
-		return diag.FromErr(err)
-	}
+// emailTemplateFromResourceData builds the IAM template, the message is sent base64 encoded
+func emailTemplateFromResourceData(d *schema.ResourceData) iam.EmailTemplate {
 	var template iam.EmailTemplate
 
+	template.ID = d.Id()
 	template.Type = d.Get("type").(string)
 	template.Format = d.Get("format").(string)
 	template.Subject = d.Get("subject").(string)
@@ -91,17 +87,95 @@ func resourceIAMEmailTemplateCreate(_ context.Context, d *schema.ResourceData, m
 	template.Locale = d.Get("locale").(string)
 	template.From = d.Get("from").(string)
 	template.ManagingOrganization = d.Get("managing_organization").(string)
+	return template
+}
 
-	createdTemplate, _, err := client.EmailTemplates.CreateTemplate(template)
+// parseEmailTemplateImportID splits an org_id/type[/locale] import ID. The
+// locale defaults to the default template of the org
+func parseEmailTemplateImportID(id string) (string, string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("%w: %q, expected org_id/type[/locale]", ErrInvalidEmailTemplateID, id)
+	}
+	locale := "default"
+	if len(parts) == 3 && parts[2] != "" {
+		locale = parts[2]
+	}
+	return parts[0], strings.ToUpper(parts[1]), locale, nil
+}
+
+// importEmailTemplateContext imports a template by GUID or by org_id/type[/locale]
+func importEmailTemplateContext(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	config := m.(*Config)
+
+	if !strings.Contains(d.Id(), "/") {
+		return []*schema.ResourceData{d}, nil
+	}
+	orgID, templateType, locale, err := parseEmailTemplateImportID(d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, err
+	}
+	client, err := config.IAMClient()
+	if err != nil {
+		return nil, err
 	}
+	query := url.Values{
+		"organizationId": {orgID},
+		"type":           {templateType},
+		"locale":         {locale},
+	}
+	templates, _, err := searchEmailTemplates(ctx, config, client, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w: no %s template with locale %s in org %s", ErrResourceNotFound, templateType, locale, orgID)
+	}
+	d.SetId(templates[0].ID)
+	_ = d.Set("managing_organization", orgID)
+	return []*schema.ResourceData{d}, nil
+}
+
+// searchEmailTemplates returns the templates matching query. The search only
+// returns template IDs and GetTemplate of go-hsdp-api stops at the first one
+func searchEmailTemplates(ctx context.Context, config *Config, client *iam.Client, query url.Values) ([]iam.EmailTemplate, *http.Response, error) {
+	var bundle struct {
+		Entry []struct {
+			ID string `json:"id"`
+		} `json:"entry"`
+	}
+	resp, err := config.iamRequest(ctx, client, http.MethodGet, "/authorize/identity/EmailTemplate?"+query.Encode(), nil, &bundle)
+	if err != nil {
+		return nil, resp, err
+	}
+	templates := make([]iam.EmailTemplate, len(bundle.Entry))
+	for i, e := range bundle.Entry {
+		resp, err = config.iamRequest(ctx, client, http.MethodGet, "/authorize/identity/EmailTemplate/"+e.ID, nil, &templates[i])
+		if err != nil {
+			return nil, resp, err
+		}
+	}
+	return templates, resp, nil
+}
+
+func resourceIAMEmailTemplateCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_email_template", d.Id())
+
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	template := emailTemplateFromResourceData(d)
+	template.ID = ""
+
+	createdTemplate, resp, err := client.EmailTemplates.CreateTemplate(template)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
 	d.SetId(createdTemplate.ID)
-	d.Set("message_base64", createdTemplate.Message)
-	return diags
+	_ = d.Set("message_base64", createdTemplate.Message)
+	return resourceIAMEmailTemplateRead(ctx, d, m)
 }
 
 func resourceIAMEmailTemplateRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -109,47 +183,80 @@ func resourceIAMEmailTemplateRead(_ context.Context, d *schema.ResourceData, m i
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	template, _, err := client.EmailTemplates.GetTemplateByID(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
-	}
+	template, resp, err := client.EmailTemplates.GetTemplateByID(d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return diags
+		}
+		return diagFromResponse(err, resp)
 	}
-	d.Set("subject", template.Subject)
+	_ = d.Set("subject", template.Subject)
 	if template.Locale != "default" {
-		d.Set("locale", template.Locale)
+		_ = d.Set("locale", template.Locale)
+	}
+	_ = d.Set("from", template.From)
+	_ = d.Set("format", template.Format)
+	_ = d.Set("type", template.Type)
+	_ = d.Set("link", template.Link)
+	if template.ManagingOrganization != "" {
+		_ = d.Set("managing_organization", template.ManagingOrganization)
 	}
-	d.Set("from", template.From)
-	d.Set("format", template.Format)
-	d.Set("type", template.Type)
-	d.Set("link", template.Link)
-	// Message is not returned in the read call
+	// Message is not always returned in the read call, so it is not read back
 
 	d.SetId(template.ID)
 	return diags
 }
 
+func resourceIAMEmailTemplateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_email_template", d.Id())
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !d.HasChanges("from", "format", "subject", "message", "link") {
+		return resourceIAMEmailTemplateRead(ctx, d, m)
+	}
+	template := emailTemplateFromResourceData(d)
+
+	resp, err := config.iamRequest(ctx, client, http.MethodPut, "/authorize/identity/EmailTemplate/"+d.Id(), template, nil)
+	if resp != nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		// Deleting and recreating the template would serve the IAM default
+		// template in between, so that is left to an explicit replace. The
+		// prior state is kept so the change is planned again
+		d.Partial(true)
+		return diagFromResponse(fmt.Errorf("in-place update of email template %s: %w, use terraform taint to replace it",
+			d.Id(), ErrNotImplementedByHSDP), resp)
+	}
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	_ = d.Set("message_base64", template.Message)
+	return resourceIAMEmailTemplateRead(ctx, d, m)
+}
+
 func resourceIAMEmailTemplateDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	var template iam.EmailTemplate
 	template.ID = d.Id()
-	ok, _, err := client.EmailTemplates.DeleteTemplate(template)
+	ok, resp, err := client.EmailTemplates.DeleteTemplate(template)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ok {
 		d.SetId("")
@@ -0,0 +1,177 @@
+package hsdp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMEmailTemplateConfig(f *fakeHSDP, subject string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_email_template" "test" {
+  type                  = "PASSWORD_CHANGED"
+  managing_organization = "fake-root-org"
+  locale                = "en-US"
+  subject               = %q
+  message               = "Dear {{user.givenName}}, your password was changed"
+}
+
+data "hsdp_iam_email_templates" "test" {
+  managing_organization = "fake-root-org"
+  type                  = "PASSWORD_CHANGED"
+
+  placeholders = {
+    "user.givenName" = "John"
+  }
+
+  depends_on = [hsdp_iam_email_template.test]
+}
+`, subject)
+}
+
+// testCheckEmailTemplateInPlace verifies the template was not recreated
+func testCheckEmailTemplateInPlace(id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs := s.RootModule().Resources["hsdp_iam_email_template.test"]
+		if *id != "" && rs.Primary.ID != *id {
+			return fmt.Errorf("template was recreated: %s != %s", rs.Primary.ID, *id)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+func TestResourceIAMEmailTemplateUpdate(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMEmailTemplateConfig(f, "Password changed"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckEmailTemplateInPlace(&id),
+					resource.TestCheckResourceAttr("data.hsdp_iam_email_templates.test", "templates.#", "1"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_email_templates.test", "templates.0.preview_message",
+						"Dear John, your password was changed"),
+				),
+			},
+			{
+				Config: testIAMEmailTemplateConfig(f, "Hi {{user.givenName}}, your password changed"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckEmailTemplateInPlace(&id),
+					resource.TestCheckResourceAttr("hsdp_iam_email_template.test", "subject",
+						"Hi {{user.givenName}}, your password changed"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_email_templates.test", "templates.0.preview_subject",
+						"Hi John, your password changed"),
+				),
+			},
+			{
+				ResourceName:            "hsdp_iam_email_template.test",
+				ImportState:             true,
+				ImportStateId:           "fake-root-org/PASSWORD_CHANGED/en-US",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"message", "message_base64"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMEmailTemplateUpdateNotAllowed(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMEmailTemplateConfig(f, "Password changed"),
+				Check:  testCheckEmailTemplateInPlace(&id),
+			},
+			{
+				// Without in-place updates the template must not be deleted
+				PreConfig: func() {
+					f.failNext(http.MethodPut, "/authorize/identity/EmailTemplate/", http.StatusMethodNotAllowed, 1)
+				},
+				Config:      testIAMEmailTemplateConfig(f, "Your password changed"),
+				ExpectError: regexp.MustCompile("not implemented by HSDP"),
+			},
+			{
+				// The failed change is still pending and applied once IAM accepts it
+				Config: testIAMEmailTemplateConfig(f, "Your password changed"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckEmailTemplateInPlace(&id),
+					func(s *terraform.State) error {
+						if subject := f.get("EmailTemplate", id)["subject"]; subject != "Your password changed" {
+							return fmt.Errorf("expected subject to be updated, got %v", subject)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestRenderEmailTemplate(t *testing.T) {
+	rendered := renderEmailTemplate("Hi {{ user.givenName }} {{user.familyName}}, {{unknown.value}}", emailPlaceholderSamples)
+	assert.Equal(t, "Hi Jane Doe, {{unknown.value}}", rendered)
+
+	_, _, _, err := parseEmailTemplateImportID("fake-root-org")
+	assert.True(t, errors.Is(err, ErrInvalidEmailTemplateID))
+	org, templateType, locale, err := parseEmailTemplateImportID("fake-root-org/password_changed")
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-root-org", org)
+	assert.Equal(t, "PASSWORD_CHANGED", templateType)
+	assert.Equal(t, "default", locale)
+}
+
+func TestResourceIAMEmailTemplateDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMEmailTemplateConfig(f, "Password changed"),
+				Check:  testSaveID("hsdp_iam_email_template.test", &id),
+			},
+			{
+				// Deleting a template makes IAM fall back to its default, Read
+				// gets a 404 and the template is created again
+				PreConfig: func() {
+					f.remove("EmailTemplate", id)
+				},
+				Config: testIAMEmailTemplateConfig(f, "Password changed"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeRecreated(f, "EmailTemplate", "hsdp_iam_email_template.test", &id),
+					testCheckFakeCount(f, "EmailTemplate", 1),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMEmailTemplateRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	// Templates are read by ID, which is answered by a gateway timing out
+	f.failNext(http.MethodGet, "/authorize/identity/EmailTemplate/", http.StatusGatewayTimeout, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMEmailTemplateConfig(f, "Password changed"),
+				Check:  testCheckFakeExists(f, "EmailTemplate", "hsdp_iam_email_template.test"),
+			},
+		},
+	})
+}
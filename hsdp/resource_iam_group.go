@@ -2,10 +2,26 @@ package hsdp
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/philips-software/go-hsdp-api/iam"
-	"net/http"
+)
+
+const (
+	// membershipAuthoritative removes users and services which are not in the configuration
+	membershipAuthoritative = "authoritative"
+	// membershipAdditive only manages the users and services in the configuration
+	membershipAdditive = "additive"
+)
+
+const (
+	servicesPageSize = 100
+	groupsPageSize   = 100
 )
 
 func resourceIAMGroup() *schema.Resource {
@@ -20,6 +36,7 @@ func resourceIAMGroup() *schema.Resource {
 		DeleteContext: resourceIAMGroupDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": {
 				Type:             schema.TypeString,
 				Required:         true,
@@ -52,16 +69,105 @@ func resourceIAMGroup() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"membership_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      membershipAuthoritative,
+				ValidateFunc: validation.StringInSlice([]string{membershipAuthoritative, membershipAdditive}, false),
+			},
 		},
 	}
 }
 
+// groupUsers pages through the users of a group
+func groupUsers(client *iam.Client, groupID string) ([]string, *iam.Response, error) {
+	return listUsers(client, iam.GetUserOptions{GroupID: &groupID})
+}
+
+// groupServices pages through the service identities of a group. GetServices
+// of go-hsdp-api only returns the first page so the search is done directly
+func groupServices(ctx context.Context, config *Config, client *iam.Client, groupID string) ([]string, *http.Response, error) {
+	var ids []string
+	for page := 1; ; page++ {
+		query := url.Values{
+			"groupId": {groupID},
+			"_count":  {strconv.Itoa(servicesPageSize)},
+			"_page":   {strconv.Itoa(page)},
+		}
+		var bundle struct {
+			Total int `json:"total"`
+			Entry []struct {
+				ID string `json:"id"`
+			} `json:"entry"`
+		}
+		resp, err := config.iamRequest(ctx, client, http.MethodGet, "/authorize/identity/Service?"+query.Encode(), nil, &bundle)
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, e := range bundle.Entry {
+			ids = append(ids, e.ID)
+		}
+		// total is optional in the bundle, without it paging ends on a short page
+		if len(bundle.Entry) < servicesPageSize || (bundle.Total > 0 && len(ids) >= bundle.Total) {
+			return ids, resp, nil
+		}
+	}
+}
+
+// searchGroups pages through the groups matching query. GetGroup of
+// go-hsdp-api only returns the first match and sends the organization as a
+// parameter IAM does not know, so the search is done directly. Group searches
+// return the groups in the resource of each entry
+func searchGroups(ctx context.Context, config *Config, client *iam.Client, query url.Values) ([]iam.Group, *http.Response, error) {
+	var groups []iam.Group
+	for page := 1; ; page++ {
+		query.Set("_count", strconv.Itoa(groupsPageSize))
+		query.Set("_page", strconv.Itoa(page))
+		var bundle struct {
+			Total int `json:"total"`
+			Entry []struct {
+				Resource struct {
+					ID          string `json:"_id"`
+					Name        string `json:"groupName"`
+					Description string `json:"groupDescription"`
+					OrgID       string `json:"orgId"`
+				} `json:"resource"`
+			} `json:"entry"`
+		}
+		resp, err := config.iamRequest(ctx, client, http.MethodGet, "/authorize/identity/Group?"+query.Encode(), nil, &bundle)
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, e := range bundle.Entry {
+			groups = append(groups, iam.Group{
+				ID:                   e.Resource.ID,
+				Name:                 e.Resource.Name,
+				Description:          e.Resource.Description,
+				ManagingOrganization: e.Resource.OrgID,
+			})
+		}
+		// total is optional in the bundle, without it paging ends on a short page
+		if len(bundle.Entry) < groupsPageSize || (bundle.Total > 0 && len(groups) >= bundle.Total) {
+			return groups, resp, nil
+		}
+	}
+}
+
+// managedMembers returns the members of a group Terraform tracks in key. In
+// additive mode members which were added outside of Terraform are left alone
+func managedMembers(d *schema.ResourceData, key string, members []string) []string {
+	if d.Get("membership_mode").(string) != membershipAdditive {
+		return members
+	}
+	return intersection(members, expandStringList(d.Get(key).(*schema.Set).List()))
+}
+
 func resourceIAMGroupCreate(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -71,9 +177,9 @@ func resourceIAMGroupCreate(_ context.Context, d *schema.ResourceData, m interfa
 	group.Name = d.Get("name").(string)
 	group.ManagingOrganization = d.Get("managing_organization").(string)
 
-	createdGroup, _, err := client.Groups.CreateGroup(group)
+	createdGroup, resp, err := client.Groups.CreateGroup(group)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	roles := expandStringList(d.Get("roles").(*schema.Set).List())
 
@@ -84,41 +190,44 @@ func resourceIAMGroupCreate(_ context.Context, d *schema.ResourceData, m interfa
 
 	// Add roles
 	for _, r := range roles {
-		role, _, _ := client.Roles.GetRoleByID(r)
-		if role != nil {
-			_, _, err = client.Groups.AssignRole(*createdGroup, *role)
-			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
-			}
+		role, resp, err := client.Roles.GetRoleByID(r)
+		if err != nil {
+			diags = append(diags, diagFromResponse(err, resp, "roles")...)
+			continue
+		}
+		_, resp, err = client.Groups.AssignRole(*createdGroup, *role)
+		if err != nil {
+			diags = append(diags, diagFromResponse(err, resp, "roles")...)
 		}
 	}
 
 	// Add users
 	users := expandStringList(d.Get("users").(*schema.Set).List())
 	if len(users) > 0 {
-		_, _, err = client.Groups.AddMembers(*createdGroup, users...)
+		_, resp, err = client.Groups.AddMembers(*createdGroup, users...)
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, diagFromResponse(err, resp, "users")...)
 		}
 	}
 
 	// Add services
 	services := expandStringList(d.Get("services").(*schema.Set).List())
 	if len(services) > 0 {
-		_, _, err = client.Groups.AddServices(*createdGroup, services...)
+		_, resp, err = client.Groups.AddServices(*createdGroup, services...)
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, diagFromResponse(err, resp, "services")...)
 		}
 	}
 	return diags
 }
 
-func resourceIAMGroupRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceIAMGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_group", d.Id())
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -130,20 +239,34 @@ func resourceIAMGroupRead(_ context.Context, d *schema.ResourceData, m interface
 			d.SetId("")
 			return diags
 		}
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("managing_organization", group.ManagingOrganization)
 	_ = d.Set("description", group.Description)
 	_ = d.Set("name", group.Name)
-	roles, _, err := client.Groups.GetRoles(*group)
+	roles, resp, err := client.Groups.GetRoles(*group)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp, "roles")
 	}
 	roleIDs := make([]string, len(*roles))
 	for i, r := range *roles {
 		roleIDs[i] = r.ID
 	}
 	_ = d.Set("roles", &roleIDs)
+
+	users, resp, err := groupUsers(client, group.ID)
+	if err != nil {
+		return diagFromResponse(err, resp, "users")
+	}
+	services, httpResp, err := groupServices(ctx, config, client, group.ID)
+	if err != nil {
+		return diagFromResponse(err, httpResp, "services")
+	}
+	_ = d.Set("users", managedMembers(d, "users", users))
+	_ = d.Set("services", managedMembers(d, "services", services))
+	if d.Get("membership_mode").(string) == "" {
+		_ = d.Set("membership_mode", membershipAuthoritative)
+	}
 	return diags
 }
 
@@ -152,7 +275,7 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -161,9 +284,9 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 	group.ID = d.Id()
 	if d.HasChange("description") {
 		group.Description = d.Get("description").(string)
-		_, _, err := client.Groups.UpdateGroup(group)
+		_, resp, err := client.Groups.UpdateGroup(group)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp, "description")
 		}
 	}
 	// Users
@@ -175,10 +298,16 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 		toRemove := difference(old, newList)
 
 		if len(toRemove) > 0 {
-			_, _, _ = client.Groups.RemoveMembers(group, toRemove...)
+			_, resp, err := client.Groups.RemoveMembers(group, toRemove...)
+			if err != nil {
+				diags = append(diags, diagFromResponse(err, resp, "users")...)
+			}
 		}
 		if len(toAdd) > 0 {
-			_, _, _ = client.Groups.AddMembers(group, toAdd...)
+			_, resp, err := client.Groups.AddMembers(group, toAdd...)
+			if err != nil {
+				diags = append(diags, diagFromResponse(err, resp, "users")...)
+			}
 		}
 	}
 
@@ -191,10 +320,16 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 		toRemove := difference(old, newList)
 
 		if len(toRemove) > 0 {
-			_, _, _ = client.Groups.RemoveServices(group, toRemove...)
+			_, resp, err := client.Groups.RemoveServices(group, toRemove...)
+			if err != nil {
+				diags = append(diags, diagFromResponse(err, resp, "services")...)
+			}
 		}
 		if len(toAdd) > 0 {
-			_, _, _ = client.Groups.AddServices(group, toAdd...)
+			_, resp, err := client.Groups.AddServices(group, toAdd...)
+			if err != nil {
+				diags = append(diags, diagFromResponse(err, resp, "services")...)
+			}
 		}
 	}
 
@@ -209,9 +344,9 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 		if len(toAdd) > 0 {
 			for _, v := range toAdd {
 				var role = iam.Role{ID: v}
-				_, _, err := client.Groups.AssignRole(group, role)
+				_, resp, err := client.Groups.AssignRole(group, role)
 				if err != nil {
-					return diag.FromErr(err)
+					return append(diags, diagFromResponse(err, resp, "roles")...)
 				}
 			}
 		}
@@ -219,9 +354,9 @@ func resourceIAMGroupUpdate(_ context.Context, d *schema.ResourceData, m interfa
 		// Remove every role. Simpler to remove and add newValues ones,
 		for _, v := range toRemove {
 			var role = iam.Role{ID: v}
-			_, _, err := client.Groups.RemoveRole(group, role)
+			_, resp, err := client.Groups.RemoveRole(group, role)
 			if err != nil {
-				return diag.FromErr(err)
+				return append(diags, diagFromResponse(err, resp, "roles")...)
 			}
 		}
 
@@ -234,7 +369,7 @@ func resourceIAMGroupDelete(_ context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -245,24 +380,24 @@ func resourceIAMGroupDelete(_ context.Context, d *schema.ResourceData, m interfa
 	// Remove all (known) users first before attempting delete
 	users := expandStringList(d.Get("users").(*schema.Set).List())
 	if len(users) > 0 {
-		_, _, err := client.Groups.RemoveMembers(group, users...)
+		_, resp, err := client.Groups.RemoveMembers(group, users...)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp, "users")
 		}
 	}
 
 	// Remove all (known) users first before attempting delete
 	services := expandStringList(d.Get("services").(*schema.Set).List())
 	if len(services) > 0 {
-		_, _, err := client.Groups.RemoveServices(group, services...)
+		_, resp, err := client.Groups.RemoveServices(group, services...)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp, "services")
 		}
 	}
 
-	ok, _, err := client.Groups.DeleteGroup(group)
+	ok, resp, err := client.Groups.DeleteGroup(group)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrDeleteGroupFailed)
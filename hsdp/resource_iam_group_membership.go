@@ -0,0 +1,170 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+const (
+	memberTypeUser    = "user"
+	memberTypeService = "service"
+)
+
+func resourceIAMGroupMembership() *schema.Resource {
+	return &schema.Resource{
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CreateContext: resourceIAMGroupMembershipCreate,
+		ReadContext:   resourceIAMGroupMembershipRead,
+		UpdateContext: updateCredentialsOnly,
+		DeleteContext: resourceIAMGroupMembershipDelete,
+
+		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
+			"group_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"member_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"member_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      memberTypeUser,
+				ValidateFunc: validation.StringInSlice([]string{memberTypeUser, memberTypeService}, false),
+			},
+		},
+	}
+}
+
+// parseGroupMembershipID splits a group_id/member_id resource ID
+func parseGroupMembershipID(id string) (string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%w: %q, expected group_id/member_id", ErrInvalidGroupMembershipID, id)
+	}
+	return parts[0], parts[1], nil
+}
+
+func resourceIAMGroupMembershipCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	groupID := d.Get("group_id").(string)
+	memberID := d.Get("member_id").(string)
+	group := iam.Group{ID: groupID}
+
+	if d.Get("member_type").(string) == memberTypeService {
+		_, resp, err := client.Groups.AddServices(group, memberID)
+		if err != nil {
+			return diagFromResponse(err, resp, "member_id")
+		}
+	} else {
+		_, resp, err := client.Groups.AddMembers(group, memberID)
+		if err != nil {
+			return diagFromResponse(err, resp, "member_id")
+		}
+	}
+	d.SetId(groupID + "/" + memberID)
+	return resourceIAMGroupMembershipRead(ctx, d, m)
+}
+
+func resourceIAMGroupMembershipRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	groupID, memberID, err := parseGroupMembershipID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_, resp, err := client.Groups.GetGroupByID(groupID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return diags
+		}
+		return diagFromResponse(err, resp, "group_id")
+	}
+
+	// The member type is not part of the ID so it is looked up on import
+	memberType := d.Get("member_type").(string)
+	found := false
+	if memberType != memberTypeService {
+		users, resp, err := groupUsers(client, groupID)
+		if err != nil {
+			return diagFromResponse(err, resp)
+		}
+		if found = len(intersection(users, []string{memberID})) > 0; found {
+			memberType = memberTypeUser
+		}
+	}
+	if !found && memberType != memberTypeUser {
+		services, resp, err := groupServices(ctx, config, client, groupID)
+		if err != nil {
+			return diagFromResponse(err, resp)
+		}
+		if found = len(intersection(services, []string{memberID})) > 0; found {
+			memberType = memberTypeService
+		}
+	}
+	if !found {
+		d.SetId("")
+		return diags
+	}
+	_ = d.Set("group_id", groupID)
+	_ = d.Set("member_id", memberID)
+	_ = d.Set("member_type", memberType)
+	return diags
+}
+
+func resourceIAMGroupMembershipDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	group := iam.Group{ID: d.Get("group_id").(string)}
+	memberID := d.Get("member_id").(string)
+
+	if d.Get("member_type").(string) == memberTypeService {
+		_, resp, err := client.Groups.RemoveServices(group, memberID)
+		if err != nil {
+			return diagFromResponse(err, resp, "member_id")
+		}
+	} else {
+		_, resp, err := client.Groups.RemoveMembers(group, memberID)
+		if err != nil {
+			return diagFromResponse(err, resp, "member_id")
+		}
+	}
+	d.SetId("")
+	return diags
+}
@@ -0,0 +1,137 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMGroupMembershipConfig(f *fakeHSDP) string {
+	return f.providerConfig() + `
+resource "hsdp_iam_role" "test" {
+  name                  = "TEST_GROUP_ROLE"
+  description           = "Test role"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ"]
+}
+
+resource "hsdp_iam_group" "test" {
+  name                  = "TestGroup"
+  description           = "Test group"
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.test.id]
+  users                 = ["user-1"]
+  membership_mode       = "additive"
+}
+
+resource "hsdp_iam_group_membership" "user" {
+  group_id  = hsdp_iam_group.test.id
+  member_id = "user-2"
+}
+
+resource "hsdp_iam_group_membership" "service" {
+  group_id    = hsdp_iam_group.test.id
+  member_id   = "service-1"
+  member_type = "service"
+}
+`
+}
+
+func testCheckIAMGroupMembershipDestroy(f *fakeHSDP) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "hsdp_iam_group_membership" {
+				continue
+			}
+			group := f.get("Group", rs.Primary.Attributes["group_id"])
+			if group == nil {
+				continue
+			}
+			field := "_users"
+			if rs.Primary.Attributes["member_type"] == "service" {
+				field = "_services"
+			}
+			members, _ := group[field].([]string)
+			if len(intersection(members, []string{rs.Primary.Attributes["member_id"]})) > 0 {
+				return fmt.Errorf("membership %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMGroupMembership(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupMembershipDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupMembershipConfig(f),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeGroupMembers(f, "user-1", "user-2"),
+					resource.TestCheckResourceAttr("hsdp_iam_group.test", "users.#", "1"),
+					resource.TestCheckResourceAttr("hsdp_iam_group_membership.service", "member_type", "service"),
+				),
+			},
+			{
+				Config:   testIAMGroupMembershipConfig(f),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "hsdp_iam_group_membership.user",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "hsdp_iam_group_membership.service",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupMembershipDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupMembershipDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupMembershipConfig(f),
+			},
+			{
+				// user-2 is taken out of the group in the IAM console, only that
+				// membership is gone and it is added back
+				PreConfig: func() {
+					f.setMembers(f.find("Group", "name", "TestGroup"), "user-1")
+				},
+				Config: testIAMGroupMembershipConfig(f),
+				Check:  testCheckFakeGroupMembers(f, "user-1", "user-2"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupMembershipRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.failNext(http.MethodGet, "/authorize/identity/Service", http.StatusBadGateway, 2)
+	f.failNext(http.MethodGet, "/security/users", http.StatusBadGateway, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupMembershipDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupMembershipConfig(f),
+				Check:  testCheckFakeGroupMembers(f, "user-1", "user-2"),
+			},
+		},
+	})
+}
@@ -0,0 +1,226 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMGroupConfig(f *fakeHSDP, description string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_role" "test" {
+  name                  = "TEST_GROUP_ROLE"
+  description           = "Test role"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ"]
+}
+
+resource "hsdp_iam_group" "test" {
+  name                  = "TestGroup"
+  description           = %q
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.test.id]
+}
+`, description)
+}
+
+func testCheckIAMGroupDestroy(f *fakeHSDP) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "hsdp_iam_group" {
+				continue
+			}
+			if f.get("Group", rs.Primary.ID) != nil {
+				return fmt.Errorf("group %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMGroup(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupConfig(f, "Test group"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Group", "hsdp_iam_group.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_group.test", "description", "Test group"),
+					resource.TestCheckResourceAttr("hsdp_iam_group.test", "roles.#", "1"),
+				),
+			},
+			{
+				Config: testIAMGroupConfig(f, "Updated group"),
+				Check:  resource.TestCheckResourceAttr("hsdp_iam_group.test", "description", "Updated group"),
+			},
+			{
+				ResourceName:            "hsdp_iam_group.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"users", "services"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupConfig(f, "Test group"),
+			},
+			{
+				PreConfig: func() {
+					f.remove("Group", f.find("Group", "name", "TestGroup"))
+				},
+				Config: testIAMGroupConfig(f, "Test group"),
+				Check:  testCheckFakeExists(f, "Group", "hsdp_iam_group.test"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.failNext(http.MethodPost, "/authorize/identity/Group", http.StatusTooManyRequests, 1)
+	f.failNext(http.MethodGet, "/authorize/identity/Group/", http.StatusBadGateway, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupConfig(f, "Test group"),
+				Check:  testCheckFakeExists(f, "Group", "hsdp_iam_group.test"),
+			},
+		},
+	})
+}
+
+func testIAMGroupMembersConfig(f *fakeHSDP, mode string, users []string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_role" "test" {
+  name                  = "TEST_GROUP_ROLE"
+  description           = "Test role"
+  managing_organization = "fake-root-org"
+  permissions           = ["GROUP.READ"]
+}
+
+resource "hsdp_iam_group" "test" {
+  name                  = "TestGroup"
+  description           = "Test group"
+  managing_organization = "fake-root-org"
+  roles                 = [hsdp_iam_role.test.id]
+  users                 = %s
+  membership_mode       = %q
+}
+`, hclStringList(users), mode)
+}
+
+func testCheckFakeGroupMembers(f *fakeHSDP, users ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group := f.get("Group", s.RootModule().Resources["hsdp_iam_group.test"].Primary.ID)
+		if group == nil {
+			return fmt.Errorf("group does not exist in fake")
+		}
+		members, _ := group["_users"].([]string)
+		if len(difference(members, users)) > 0 || len(difference(users, members)) > 0 {
+			return fmt.Errorf("expected members %v, got %v", users, members)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMGroupMembershipDefault(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupConfig(f, "Test group"),
+				Check:  resource.TestCheckResourceAttr("hsdp_iam_group.test", "membership_mode", "authoritative"),
+			},
+			{
+				// Members added outside of Terraform are detected and removed
+				// unless additive mode is chosen
+				PreConfig: func() {
+					f.setMembers(f.find("Group", "name", "TestGroup"), "user-2")
+				},
+				Config:             testIAMGroupConfig(f, "Test group"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testIAMGroupConfig(f, "Test group"),
+				Check:  testCheckFakeGroupMembers(f),
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupMembershipAuthoritative(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupMembersConfig(f, "authoritative", []string{"user-1"}),
+				Check:  testCheckFakeGroupMembers(f, "user-1"),
+			},
+			{
+				PreConfig: func() {
+					f.setMembers(f.find("Group", "name", "TestGroup"), "user-1", "user-2")
+				},
+				Config: testIAMGroupMembersConfig(f, "authoritative", []string{"user-1"}),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeGroupMembers(f, "user-1"),
+					resource.TestCheckResourceAttr("hsdp_iam_group.test", "users.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMGroupMembershipAdditive(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMGroupDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMGroupMembersConfig(f, "additive", []string{"user-1"}),
+				Check:  testCheckFakeGroupMembers(f, "user-1"),
+			},
+			{
+				PreConfig: func() {
+					f.setMembers(f.find("Group", "name", "TestGroup"), "user-1", "user-2")
+				},
+				Config:   testIAMGroupMembersConfig(f, "additive", []string{"user-1"}),
+				PlanOnly: true,
+			},
+			{
+				PreConfig: func() {
+					f.setMembers(f.find("Group", "name", "TestGroup"), "user-2")
+				},
+				Config: testIAMGroupMembersConfig(f, "additive", []string{"user-1"}),
+				Check:  testCheckFakeGroupMembers(f, "user-1", "user-2"),
+			},
+		},
+	})
+}
@@ -3,9 +3,10 @@ package hsdp
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"net/http"
+	"net/url"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/iam"
 )
@@ -22,6 +23,7 @@ func resourceIAMMFAPolicy() *schema.Resource {
 		DeleteContext: resourceIAMMFAPolicyDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -51,6 +53,11 @@ func resourceIAMMFAPolicy() *schema.Resource {
 				ConflictsWith: []string{"user"},
 				ForceNew:      true,
 			},
+			"adopt_existing": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"version": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
@@ -59,12 +66,34 @@ func resourceIAMMFAPolicy() *schema.Resource {
 	}
 }
 
+// orgMFAPolicies returns the MFA policies attached to the organization orgID.
+// go-hsdp-api cannot search MFA policies so the SCIM endpoint is called directly
+func orgMFAPolicies(ctx context.Context, config *Config, client *iam.Client, orgID string) ([]iam.MFAPolicy, *http.Response, error) {
+	var list struct {
+		TotalResults int             `json:"totalResults"`
+		Resources    []iam.MFAPolicy `json:"Resources"`
+	}
+	filter := url.QueryEscape(fmt.Sprintf(`resource.value eq "%s"`, orgID))
+	resp, err := config.iamRequestVersion(ctx, client, "2", http.MethodGet, "/authorize/scim/v2/MFAPolicies?filter="+filter, nil, &list)
+	if err != nil {
+		return nil, resp, err
+	}
+	var policies []iam.MFAPolicy
+	for _, policy := range list.Resources {
+		if policy.Resource.Type == "Organization" && policy.Resource.Value == orgID {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, resp, nil
+}
+
 func resourceIAMMFAPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_mfa_policy", d.Id())
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -92,9 +121,30 @@ func resourceIAMMFAPolicyCreate(ctx context.Context, d *schema.ResourceData, m i
 		policy.SetResourceUser(user)
 	}
 
+	// An org has a single MFA policy, which may have been set up in the console
+	if organization != "" && d.Get("adopt_existing").(bool) {
+		policies, resp, err := orgMFAPolicies(ctx, config, client, organization)
+		if err != nil {
+			return diagFromResponse(err, resp, "organization")
+		}
+		if len(policies) > 0 {
+			existing := policies[0]
+			existing.Name = name
+			existing.Description = description
+			existing.SetType(otpType)
+			existing.SetActive(active)
+			adoptedPolicy, resp, err := client.MFAPolicies.UpdateMFAPolicy(&existing)
+			if err != nil {
+				return diagFromResponse(err, resp)
+			}
+			d.SetId(adoptedPolicy.ID)
+			return resourceIAMMFAPolicyRead(ctx, d, m)
+		}
+	}
+
 	newPolicy, resp, err := client.MFAPolicies.CreateMFAPolicy(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if newPolicy == nil {
 		return diag.FromErr(fmt.Errorf("failed to create MFA policy: %d", resp.StatusCode))
@@ -112,7 +162,7 @@ func resourceIAMMFAPolicyRead(ctx context.Context, d *schema.ResourceData, m int
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -146,15 +196,15 @@ func resourceIAMMFAPolicyUpdate(ctx context.Context, d *schema.ResourceData, m i
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	id := d.Id()
-	policy, _, err := client.MFAPolicies.GetMFAPolicyByID(id)
+	policy, resp, err := client.MFAPolicies.GetMFAPolicyByID(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	if d.HasChange("description") {
@@ -169,9 +219,9 @@ func resourceIAMMFAPolicyUpdate(ctx context.Context, d *schema.ResourceData, m i
 		active := d.Get("active").(bool)
 		policy.Active = &active
 	}
-	updatedPolicy, _, err := client.MFAPolicies.UpdateMFAPolicy(policy)
+	updatedPolicy, resp, err := client.MFAPolicies.UpdateMFAPolicy(policy)
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, diagFromResponse(err, resp)...)
 	}
 	if updatedPolicy != nil {
 		_ = d.Set("version", updatedPolicy.Meta.Version)
@@ -184,7 +234,7 @@ func resourceIAMMFAPolicyDelete(ctx context.Context, d *schema.ResourceData, m i
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -192,9 +242,9 @@ func resourceIAMMFAPolicyDelete(ctx context.Context, d *schema.ResourceData, m i
 	var policy iam.MFAPolicy
 	policy.ID = d.Id()
 
-	ok, _, err := client.MFAPolicies.DeleteMFAPolicy(policy)
+	ok, resp, err := client.MFAPolicies.DeleteMFAPolicy(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrDeleteMFAPolicyFailed)
@@ -0,0 +1,133 @@
+package hsdp
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMMFAPolicyConfig(f *fakeHSDP, requiredType string) string {
+	return f.providerConfig() + `
+resource "hsdp_iam_mfa_policy" "test" {
+  name           = "Org MFA"
+  type           = "SOFT_OTP"
+  organization   = "fake-root-org"
+  active         = true
+  adopt_existing = true
+}
+
+data "hsdp_iam_mfa_policies" "test" {
+  organization   = "fake-root-org"
+  required_types = ["` + requiredType + `"]
+
+  depends_on = [hsdp_iam_mfa_policy.test]
+}
+`
+}
+
+func TestResourceIAMMFAPolicyAdoptExisting(t *testing.T) {
+	f := newFakeHSDP(t)
+	existingID := f.put("MFAPolicies", fakeObject{
+		"name":     "Console MFA",
+		"types":    []string{"SOFT_OTP"},
+		"active":   false,
+		"resource": fakeObject{"type": "Organization", "value": "fake-root-org"},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMMFAPolicyConfig(f, "SOFT_OTP"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_mfa_policy.test", "id", existingID),
+					resource.TestCheckResourceAttr("data.hsdp_iam_mfa_policies.test", "policies.#", "1"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_mfa_policies.test", "policies.0.name", "Org MFA"),
+					resource.TestCheckResourceAttr("data.hsdp_iam_mfa_policies.test", "active_types.#", "1"),
+				),
+			},
+			{
+				Config:      testIAMMFAPolicyConfig(f, "SERVER_OTP"),
+				ExpectError: regexp.MustCompile("does not have the required MFA policies"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMMFAPolicyDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMMFAPolicyConfig(f, "SOFT_OTP"),
+				Check:  testSaveID("hsdp_iam_mfa_policy.test", &id),
+			},
+			{
+				// The SCIM API answers a deleted policy with a 404 and the
+				// policy is recreated for the organization
+				PreConfig: func() {
+					f.remove("MFAPolicies", id)
+				},
+				Config: testIAMMFAPolicyConfig(f, "SOFT_OTP"),
+				Check:  testCheckFakeRecreated(f, "MFAPolicies", "hsdp_iam_mfa_policy.test", &id),
+			},
+			{
+				ResourceName:            "hsdp_iam_mfa_policy.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"adopt_existing"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMMFAPolicyRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	// The SCIM API is rate limited separately from the identity API
+	f.failNext(http.MethodGet, "/authorize/scim/v2/MFAPolicies/", http.StatusTooManyRequests, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMMFAPolicyConfig(f, "SOFT_OTP"),
+				Check:  testCheckFakeExists(f, "MFAPolicies", "hsdp_iam_mfa_policy.test"),
+			},
+		},
+	})
+}
+
+func TestOrgMFAPolicies(t *testing.T) {
+	f := newFakeHSDP(t)
+	id := f.put("MFAPolicies", fakeObject{
+		"name":     "Console MFA",
+		"types":    []string{"SOFT_OTP"},
+		"resource": fakeObject{"type": "Organization", "value": "fake-root-org"},
+	})
+	f.put("MFAPolicies", fakeObject{
+		"name":     "User MFA",
+		"types":    []string{"SOFT_OTP"},
+		"resource": fakeObject{"type": "User", "value": "fake-user"},
+	})
+	config := f.config()
+	client, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The fake only serves the SCIM MFAPolicies endpoint with API version 2
+	policies, _, err := orgMFAPolicies(context.Background(), config, client, "fake-root-org")
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.Len(t, policies, 1) {
+		assert.Equal(t, id, policies[0].ID)
+	}
+}
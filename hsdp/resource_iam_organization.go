@@ -22,6 +22,7 @@ func resourceIAMOrg() *schema.Resource {
 		DeleteContext: resourceIAMOrgDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
@@ -61,7 +62,7 @@ func resourceIAMOrgCreate(ctx context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -86,7 +87,7 @@ func resourceIAMOrgCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	newOrg.Type = orgType
 	org, resp, err := client.Organizations.CreateOrganization(newOrg)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if org == nil {
 		return diag.FromErr(fmt.Errorf("failed to create organization: %d", resp.StatusCode))
@@ -100,7 +101,7 @@ func resourceIAMOrgRead(ctx context.Context, d *schema.ResourceData, m interface
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -112,7 +113,7 @@ func resourceIAMOrgRead(ctx context.Context, d *schema.ResourceData, m interface
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("org_id", org.ID)
 	_ = d.Set("description", org.Description)
@@ -130,23 +131,23 @@ func resourceIAMOrgUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	id := d.Id()
-	org, _, err := client.Organizations.GetOrganizationByID(id)
+	org, resp, err := client.Organizations.GetOrganizationByID(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	if d.HasChange("description") {
 		description := d.Get("description").(string)
 		org.Description = description
-		_, _, err = client.Organizations.UpdateOrganization(*org)
+		_, resp, err = client.Organizations.UpdateOrganization(*org)
 		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+			diags = append(diags, diagFromResponse(err, resp, "description")...)
 		}
 	}
 	return diags
@@ -157,20 +158,20 @@ func resourceIAMOrgDelete(ctx context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	id := d.Id()
-	org, _, err := client.Organizations.GetOrganizationByID(id)
+	org, resp, err := client.Organizations.GetOrganizationByID(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
-	ok, _, err := client.Organizations.DeleteOrganization(*org)
+	ok, resp, err := client.Organizations.DeleteOrganization(*org)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrInvalidResponse)
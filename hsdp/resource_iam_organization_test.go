@@ -0,0 +1,93 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMOrgConfig(f *fakeHSDP, description string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_org" "test" {
+  name          = "TestOrg"
+  description   = %q
+  parent_org_id = "fake-root-org"
+}
+`, description)
+}
+
+func testCheckIAMOrgDestroy(f *fakeHSDP) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if n := f.count("Organizations"); n > 0 {
+			return fmt.Errorf("%d organizations still exist", n)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMOrg(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMOrgDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMOrgConfig(f, "Test org"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Organizations", "hsdp_iam_org.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_org.test", "description", "Test org"),
+				),
+			},
+			{
+				Config: testIAMOrgConfig(f, "Updated org"),
+				Check:  resource.TestCheckResourceAttr("hsdp_iam_org.test", "description", "Updated org"),
+			},
+			{
+				ResourceName:      "hsdp_iam_org.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMOrgDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMOrgDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMOrgConfig(f, "Test org"),
+			},
+			{
+				PreConfig: func() {
+					f.remove("Organizations", f.find("Organizations", "name", "TestOrg"))
+				},
+				Config: testIAMOrgConfig(f, "Test org"),
+				Check:  testCheckFakeExists(f, "Organizations", "hsdp_iam_org.test"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMOrgRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.failNext(http.MethodGet, "/authorize/scim/v2/Organizations/", http.StatusServiceUnavailable, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckIAMOrgDestroy(f),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMOrgConfig(f, "Test org"),
+				Check:  testCheckFakeExists(f, "Organizations", "hsdp_iam_org.test"),
+			},
+		},
+	})
+}
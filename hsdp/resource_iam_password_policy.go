@@ -3,6 +3,9 @@ package hsdp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/iam"
@@ -20,6 +23,7 @@ func resourceIAMPasswordPolicy() *schema.Resource {
 		DeleteContext: resourceIAMPasswordPolicyDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"managing_organization": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -111,6 +115,11 @@ func resourceIAMPasswordPolicy() *schema.Resource {
 					},
 				},
 			},
+			"adopt_existing": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
 			"_policy": &schema.Schema{
 				Type:     schema.TypeString,
 				Computed: true,
@@ -149,12 +158,36 @@ func resourceDataToToPasswordPolicy(d *schema.ResourceData, policy *iam.Password
 	}
 }
 
+// passwordPolicyToResourceData sets the policy arguments from an IAM password policy
+func passwordPolicyToResourceData(policy *iam.PasswordPolicy, d *schema.ResourceData) {
+	_ = d.Set("managing_organization", policy.ManagingOrganization)
+	_ = d.Set("expiry_period_in_days", policy.ExpiryPeriodInDays)
+	_ = d.Set("history_count", policy.HistoryCount)
+	_ = d.Set("complexity", []interface{}{map[string]interface{}{
+		"min_length":        policy.Complexity.MinLength,
+		"max_length":        policy.Complexity.MaxLength,
+		"min_numerics":      policy.Complexity.MinNumerics,
+		"min_uppercase":     policy.Complexity.MinUpperCase,
+		"min_lowercase":     policy.Complexity.MinLowerCase,
+		"min_special_chars": policy.Complexity.MinSpecialChars,
+	}})
+	_ = d.Set("challenges_enabled", policy.ChallengesEnabled)
+	if policy.ChallengesEnabled && policy.ChallengePolicy != nil {
+		_ = d.Set("challenge_policy", []interface{}{map[string]interface{}{
+			"default_questions":      policy.ChallengePolicy.DefaultQuestions,
+			"min_question_count":     policy.ChallengePolicy.MinQuestionCount,
+			"min_answer_count":       policy.ChallengePolicy.MinAnswerCount,
+			"max_incorrect_attempts": policy.ChallengePolicy.MaxIncorrectAttempts,
+		}})
+	}
+}
+
 func resourceIAMPasswordPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -162,23 +195,27 @@ func resourceIAMPasswordPolicyCreate(ctx context.Context, d *schema.ResourceData
 	resourceDataToToPasswordPolicy(d, &policy)
 
 	// Since there's only a single password policy per ORG, first try to fetch it
-	policies, _, err := client.PasswordPolicies.GetPasswordPolicies(&iam.GetPasswordPolicyOptions{
+	policies, resp, err := client.PasswordPolicies.GetPasswordPolicies(&iam.GetPasswordPolicyOptions{
 		OrganizationID: &policy.ManagingOrganization,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	policyFunc := client.PasswordPolicies.CreatePasswordPolicy
 	if policies != nil && len(*policies) > 0 {
+		if !d.Get("adopt_existing").(bool) {
+			return diag.FromErr(fmt.Errorf("%w: organization %s already has a password policy, set adopt_existing to manage it",
+				ErrPolicyAlreadyExists, policy.ManagingOrganization))
+		}
 		existingPolicy := (*policies)[0]
 		policy.ID = existingPolicy.ID
 		policy.Meta = existingPolicy.Meta
 		policyFunc = client.PasswordPolicies.UpdatePasswordPolicy
 	}
 
-	createdPolicy, _, err := policyFunc(policy)
+	createdPolicy, resp, err := policyFunc(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	data, err := json.Marshal(policy)
 	if err != nil {
@@ -194,24 +231,24 @@ func resourceIAMPasswordPolicyUpdate(ctx context.Context, d *schema.ResourceData
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	var updatePolicy iam.PasswordPolicy
 
-	policy, _, err := client.PasswordPolicies.GetPasswordPolicyByID(d.Id())
+	policy, resp, err := client.PasswordPolicies.GetPasswordPolicyByID(d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	resourceDataToToPasswordPolicy(d, &updatePolicy)
 	updatePolicy.ID = policy.ID
 	updatePolicy.Meta = policy.Meta
 
-	updatedPolicy, _, err := client.PasswordPolicies.UpdatePasswordPolicy(updatePolicy)
+	updatedPolicy, resp, err := client.PasswordPolicies.UpdatePasswordPolicy(updatePolicy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	data, err := json.Marshal(updatedPolicy)
 	if err != nil {
@@ -229,20 +266,25 @@ func resourceIAMPasswordPolicyRead(ctx context.Context, d *schema.ResourceData,
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	policy, _, err := client.PasswordPolicies.GetPasswordPolicyByID(d.Id())
+	policy, resp, err := client.PasswordPolicies.GetPasswordPolicyByID(d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return diags
+		}
+		return diagFromResponse(err, resp)
 	}
 	data, err := json.Marshal(policy)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	_ = d.Set("_policy", data)
+	_ = d.Set("_policy", string(data))
+	passwordPolicyToResourceData(policy, d)
 	d.SetId(policy.ID)
 	return diags
 }
@@ -252,16 +294,16 @@ func resourceIAMPasswordPolicyDelete(ctx context.Context, d *schema.ResourceData
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	var policy iam.PasswordPolicy
 	policy.ID = d.Id()
-	ok, _, err := client.PasswordPolicies.DeletePasswordPolicy(policy)
+	ok, resp, err := client.PasswordPolicies.DeletePasswordPolicy(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ok {
 		d.SetId("")
@@ -0,0 +1,130 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testIAMPasswordPolicyConfig(f *fakeHSDP, adopt bool, requiredLength int) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_password_policy" "test" {
+  managing_organization = "fake-root-org"
+  expiry_period_in_days = 180
+  adopt_existing        = %t
+
+  complexity {
+    min_length = 10
+  }
+}
+
+data "hsdp_iam_password_policy" "test" {
+  managing_organization = "fake-root-org"
+
+  required_complexity {
+    min_length = %d
+  }
+
+  depends_on = [hsdp_iam_password_policy.test]
+}
+`, adopt, requiredLength)
+}
+
+func TestResourceIAMPasswordPolicyAdoptExisting(t *testing.T) {
+	f := newFakeHSDP(t)
+	existingID := f.put("PasswordPolicy", fakeObject{"managingOrganization": "fake-root-org"})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testIAMPasswordPolicyConfig(f, false, 8),
+				ExpectError: regexp.MustCompile("already has a password policy"),
+			},
+			{
+				Config: testIAMPasswordPolicyConfig(f, true, 8),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_password_policy.test", "id", existingID),
+					resource.TestCheckResourceAttr("data.hsdp_iam_password_policy.test", "id", existingID),
+					resource.TestCheckResourceAttr("data.hsdp_iam_password_policy.test", "expiry_period_in_days", "180"),
+				),
+			},
+			{
+				Config:      testIAMPasswordPolicyConfig(f, true, 12),
+				ExpectError: regexp.MustCompile("does not meet the required complexity"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMPasswordPolicyAdoptByDefault(t *testing.T) {
+	f := newFakeHSDP(t)
+	existingID := f.put("PasswordPolicy", fakeObject{"managingOrganization": "fake-root-org"})
+	config := strings.Replace(testIAMPasswordPolicyConfig(f, true, 8), "adopt_existing        = true\n", "", 1)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_password_policy.test", "id", existingID),
+					resource.TestCheckResourceAttr("hsdp_iam_password_policy.test", "adopt_existing", "true"),
+					testCheckFakeCount(f, "PasswordPolicy", 1),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMPasswordPolicyDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMPasswordPolicyConfig(f, true, 8),
+				Check:  testSaveID("hsdp_iam_password_policy.test", &id),
+			},
+			{
+				// Deleted outside of Terraform, which leaves nothing to adopt:
+				// a single new policy is created for the organization
+				PreConfig: func() {
+					f.remove("PasswordPolicy", id)
+				},
+				Config: testIAMPasswordPolicyConfig(f, true, 8),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeRecreated(f, "PasswordPolicy", "hsdp_iam_password_policy.test", &id),
+					testCheckFakeCount(f, "PasswordPolicy", 1),
+				),
+			},
+			{
+				ResourceName:            "hsdp_iam_password_policy.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"adopt_existing"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMPasswordPolicyRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.failNext(http.MethodGet, "/authorize/identity/PasswordPolicy/", http.StatusBadGateway, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMPasswordPolicyConfig(f, true, 8),
+				Check:  testCheckFakeExists(f, "PasswordPolicy", "hsdp_iam_password_policy.test"),
+			},
+		},
+	})
+}
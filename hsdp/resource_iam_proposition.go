@@ -2,6 +2,7 @@ package hsdp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"net/http"
@@ -18,9 +19,11 @@ func resourceIAMProposition() *schema.Resource {
 
 		CreateContext: resourceIAMPropositionCreate,
 		ReadContext:   resourceIAMPropositionRead,
+		UpdateContext: updateCredentialsOnly,
 		DeleteContext: resourceIAMPropositionDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -51,7 +54,7 @@ func resourceIAMPropositionCreate(_ context.Context, d *schema.ResourceData, m i
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -68,13 +71,13 @@ func resourceIAMPropositionCreate(_ context.Context, d *schema.ResourceData, m i
 			return diag.FromErr(err)
 		}
 		if resp.StatusCode != http.StatusConflict {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
-		createdProp, _, err = client.Propositions.GetProposition(&iam.GetPropositionsOptions{
+		createdProp, resp, err = client.Propositions.GetProposition(&iam.GetPropositionsOptions{
 			Name: &prop.Name,
 		})
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		if createdProp.Description != prop.Description {
 			return diag.FromErr(fmt.Errorf("existing proposition found but description mismatch: '%s' != '%s'", createdProp.Description, prop.Description))
@@ -100,7 +103,7 @@ func resourceIAMPropositionRead(_ context.Context, d *schema.ResourceData, m int
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -108,7 +111,8 @@ func resourceIAMPropositionRead(_ context.Context, d *schema.ResourceData, m int
 	id := d.Id()
 	prop, resp, err := client.Propositions.GetPropositionByID(id)
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// A proposition which is gone is not found by the search behind GetPropositionByID
+		if errors.Is(err, iam.ErrEmptyResults) || (resp != nil && resp.StatusCode == http.StatusNotFound) {
 			d.SetId("")
 			return diags
 		}
@@ -0,0 +1,116 @@
+package hsdp
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMPropositionConfig(f *fakeHSDP, description string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_proposition" "test" {
+  name                = "TESTPROPOSITION"
+  description         = %q
+  organization_id     = "fake-root-org"
+  global_reference_id = "test-proposition-ref"
+}
+`, description)
+}
+
+func TestResourceIAMProposition(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			return nil // IAM does not support deleting propositions
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMPropositionConfig(f, "Test proposition"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Proposition", "hsdp_iam_proposition.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_proposition.test", "organization_id", "fake-root-org"),
+					resource.TestCheckResourceAttr("hsdp_iam_proposition.test", "global_reference_id", "test-proposition-ref"),
+				),
+			},
+			{
+				ResourceName:      "hsdp_iam_proposition.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMPropositionAdoptExisting(t *testing.T) {
+	f := newFakeHSDP(t)
+	id := f.put("Proposition", fakeObject{
+		"name":              "TESTPROPOSITION",
+		"description":       "Test proposition",
+		"organizationId":    "fake-root-org",
+		"globalReferenceId": "test-proposition-ref",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				// Propositions can not be deleted, so an identical one left
+				// behind by an earlier apply is taken over on the conflict
+				Config: testIAMPropositionConfig(f, "Test proposition"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_proposition.test", "id", id),
+					testCheckFakeCount(f, "Proposition", 1),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMPropositionExistingMismatch(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.put("Proposition", fakeObject{
+		"name":              "TESTPROPOSITION",
+		"description":       "Someone else's proposition",
+		"organizationId":    "fake-root-org",
+		"globalReferenceId": "test-proposition-ref",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testIAMPropositionConfig(f, "Test proposition"),
+				ExpectError: regexp.MustCompile("existing proposition found but description mismatch"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMPropositionDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMPropositionConfig(f, "Test proposition"),
+				Check:  testSaveID("hsdp_iam_proposition.test", &id),
+			},
+			{
+				// The ID search answers a missing proposition with an empty
+				// bundle and a 200, which Read must take as gone
+				PreConfig: func() {
+					f.remove("Proposition", id)
+				},
+				Config: testIAMPropositionConfig(f, "Test proposition"),
+				Check:  testCheckFakeRecreated(f, "Proposition", "hsdp_iam_proposition.test", &id),
+			},
+		},
+	})
+}
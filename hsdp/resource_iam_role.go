@@ -3,8 +3,13 @@ package hsdp
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"log"
 	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/iam"
@@ -20,8 +25,10 @@ func resourceIAMRole() *schema.Resource {
 		ReadContext:   resourceIAMRoleRead,
 		UpdateContext: resourceIAMRoleUpdate,
 		DeleteContext: resourceIAMRoleDelete,
+		CustomizeDiff: validateRolePermissions,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -54,12 +61,68 @@ func resourceIAMRole() *schema.Resource {
 	}
 }
 
+// validateRolePermissions checks the permissions of a role against the IAM
+// permission catalogue so typos are reported at plan time instead of during apply
+func validateRolePermissions(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("permissions") {
+		return nil
+	}
+	config := meta.(*Config)
+	catalogue, err := config.permissionCatalogue()
+	if err != nil || len(catalogue) == 0 {
+		// Not every identity may list permissions, IAM still rejects unknown
+		// ones on apply. The warning is reported by create and update
+		log.Printf("[WARN] skipping validation of role permissions: %v", err)
+		return nil
+	}
+	known := make(map[string]bool, len(catalogue))
+	for _, p := range catalogue {
+		known[p] = true
+	}
+	var unknown []string
+	for _, p := range expandStringList(d.Get("permissions").(*schema.Set).List()) {
+		if known[p] {
+			continue
+		}
+		message := fmt.Sprintf("%q is not a known IAM permission", p)
+		if suggestions := suggestPermissions(p, catalogue); len(suggestions) > 0 {
+			message += ", did you mean " + strings.Join(suggestions, " or ") + "?"
+		}
+		unknown = append(unknown, message)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("invalid permissions:\n%s", strings.Join(unknown, "\n"))
+	}
+	return nil
+}
+
+// permissionsNotValidated warns when the permissions of a role could not be
+// checked against the IAM permission catalogue. CustomizeDiff cannot return
+// warnings so create and update report it
+func permissionsNotValidated(config *Config) diag.Diagnostics {
+	catalogue, err := config.permissionCatalogue()
+	if err == nil && len(catalogue) > 0 {
+		return nil
+	}
+	detail := "the IAM permission catalogue is empty"
+	if err != nil {
+		detail = fmt.Sprintf("listing the IAM permission catalogue failed: %v", err)
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Warning,
+		Summary:       "role permissions were not validated",
+		Detail:        detail + ", unknown permissions are only reported by IAM",
+		AttributePath: cty.GetAttrPath("permissions"),
+	}}
+}
+
 func resourceIAMRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	config := meta.(*Config)
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -68,6 +131,7 @@ func resourceIAMRoleCreate(ctx context.Context, d *schema.ResourceData, meta int
 	description := d.Get("description").(string)
 	managingOrganization := d.Get("managing_organization").(string)
 	permissions := expandStringList(d.Get("permissions").(*schema.Set).List())
+	diags = append(diags, permissionsNotValidated(config)...)
 
 	role, resp, err := client.Roles.CreateRole(name, description, managingOrganization)
 	if err != nil {
@@ -75,15 +139,15 @@ func resourceIAMRoleCreate(ctx context.Context, d *schema.ResourceData, meta int
 			return diag.FromErr(fmt.Errorf("response is nil: %v", err))
 		}
 		if resp.StatusCode != http.StatusConflict {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		// Already exists most likely, adopt it
 		var roles *[]iam.Role
-		roles, _, err = client.Roles.GetRoles(&iam.GetRolesOptions{
+		roles, resp, err = client.Roles.GetRoles(&iam.GetRolesOptions{
 			Name: &name,
 		})
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromResponse(err, resp)
 		}
 		if len(*roles) == 0 || (*roles)[0].ManagingOrganization != managingOrganization {
 			return diag.FromErr(fmt.Errorf("conflict creating, but no role match found"))
@@ -91,7 +155,10 @@ func resourceIAMRoleCreate(ctx context.Context, d *schema.ResourceData, meta int
 		role = &(*roles)[0]
 	}
 	for _, p := range permissions {
-		_, _, _ = client.Roles.AddRolePermission(*role, p)
+		_, resp, err := client.Roles.AddRolePermission(*role, p)
+		if err != nil {
+			diags = append(diags, diagFromResponse(err, resp, "permissions")...)
+		}
 	}
 	d.SetId(role.ID)
 	readDiags := resourceIAMRoleRead(ctx, d, meta)
@@ -106,7 +173,7 @@ func resourceIAMRoleRead(ctx context.Context, d *schema.ResourceData, meta inter
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -118,15 +185,15 @@ func resourceIAMRoleRead(ctx context.Context, d *schema.ResourceData, meta inter
 			d.SetId("")
 			return diags
 		}
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("description", role.Description)
 	_ = d.Set("name", role.Name)
 	_ = d.Set("managing_organization", role.ManagingOrganization)
 
-	permissions, _, err := client.Roles.GetRolePermissions(*role)
+	permissions, resp, err := client.Roles.GetRolePermissions(*role)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp, "permissions")
 	}
 	_ = d.Set("permissions", permissions)
 	d.SetId(role.ID)
@@ -138,15 +205,15 @@ func resourceIAMRoleUpdate(ctx context.Context, d *schema.ResourceData, m interf
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	id := d.Id()
-	role, _, err := client.Roles.GetRoleByID(id)
+	role, resp, err := client.Roles.GetRoleByID(id)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 
 	if d.HasChange("description") {
@@ -154,6 +221,7 @@ func resourceIAMRoleUpdate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	if d.HasChange("permissions") {
+		diags = append(diags, permissionsNotValidated(config)...)
 		o, n := d.GetChange("permissions")
 		oldList := expandStringList(o.(*schema.Set).List())
 		newList := expandStringList(n.(*schema.Set).List())
@@ -163,9 +231,9 @@ func resourceIAMRoleUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		// Additions
 		if len(toAdd) > 0 {
 			for _, v := range toAdd {
-				_, _, err := client.Roles.AddRolePermission(*role, v)
+				_, resp, err := client.Roles.AddRolePermission(*role, v)
 				if err != nil {
-					return diag.FromErr(err)
+					return diagFromResponse(err, resp, "permissions")
 				}
 			}
 		}
@@ -176,9 +244,9 @@ func resourceIAMRoleUpdate(ctx context.Context, d *schema.ResourceData, m interf
 			if ticketProtection && v == "CLIENT.SCOPES" {
 				return diag.FromErr(fmt.Errorf("Refusing to remove CLIENT.SCOPES permission, set ticket_protection to `false` to override"))
 			}
-			_, _, err := client.Roles.RemoveRolePermission(*role, v)
+			_, resp, err := client.Roles.RemoveRolePermission(*role, v)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp, "permissions")
 			}
 		}
 
@@ -191,7 +259,7 @@ func resourceIAMRoleDelete(ctx context.Context, d *schema.ResourceData, m interf
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -199,8 +267,19 @@ func resourceIAMRoleDelete(ctx context.Context, d *schema.ResourceData, m interf
 	var role iam.Role
 	role.ID = d.Id()
 
-	_, _, _ = client.Roles.DeleteRole(role)
-	// Note: 2020-12-17, the DeleteRole call will always fail. Thanks IAM.
+	// The role stays in state when IAM refuses to delete it, so it is not
+	// orphaned. A role which is already gone is removed from state
+	ok, resp, err := client.Roles.DeleteRole(role)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	if !ok {
+		return diagFromResponse(fmt.Errorf("role %s: %w", role.ID, ErrDeleteRoleFailed), resp)
+	}
 	d.SetId("")
 	return diags
 }
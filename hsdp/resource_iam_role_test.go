@@ -0,0 +1,279 @@
+package hsdp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMRoleConfig(f *fakeHSDP, description string, permissions ...string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_role" "test" {
+  name                  = "TEST_ROLE"
+  description           = %q
+  managing_organization = "fake-root-org"
+  permissions           = %s
+}
+`, description, hclStringList(permissions))
+}
+
+func hclStringList(values []string) string {
+	list := "["
+	for i, v := range values {
+		if i > 0 {
+			list += ", "
+		}
+		list += fmt.Sprintf("%q", v)
+	}
+	return list + "]"
+}
+
+func testCheckFakeExists(f *fakeHSDP, resourceType, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found in state", name)
+		}
+		if f.get(resourceType, rs.Primary.ID) == nil {
+			return fmt.Errorf("%s %s does not exist in fake", resourceType, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+// testCheckFakeCount checks that the fake holds count objects of type, e.g.
+// that a retried create did not leave a duplicate behind
+func testCheckFakeCount(f *fakeHSDP, resourceType string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if n := f.count(resourceType); n != count {
+			return fmt.Errorf("expected %d %s objects in fake, found %d", count, resourceType, n)
+		}
+		return nil
+	}
+}
+
+// testSaveID keeps the ID of name in id for a later testCheckFakeRecreated
+func testSaveID(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found in state", name)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+// testCheckFakeRecreated checks that name exists in the fake under an ID other than oldID
+func testCheckFakeRecreated(f *fakeHSDP, resourceType, name string, oldID *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if err := testCheckFakeExists(f, resourceType, name)(s); err != nil {
+			return err
+		}
+		if id := s.RootModule().Resources[name].Primary.ID; id == *oldID {
+			return fmt.Errorf("%s was not recreated, it still has ID %s", name, id)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMRole(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			return nil // IAM does not support deleting roles
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Role", "hsdp_iam_role.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_role.test", "name", "TEST_ROLE"),
+					resource.TestCheckResourceAttr("hsdp_iam_role.test", "permissions.#", "1"),
+				),
+			},
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ", "GROUP.WRITE"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_role.test", "permissions.#", "2"),
+				),
+			},
+			{
+				ResourceName:            "hsdp_iam_role.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"ticket_protection"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+			},
+			{
+				// Role removed outside of Terraform is recreated
+				PreConfig: func() {
+					f.remove("Role", f.find("Role", "name", "TEST_ROLE"))
+				},
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				Check:  testCheckFakeExists(f, "Role", "hsdp_iam_role.test"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleDeleteFailed(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+			},
+			{
+				// IAM refuses the delete, the role must not be orphaned
+				PreConfig: func() {
+					f.failNext(http.MethodDelete, "/authorize/identity/Role/", http.StatusForbidden, 1)
+				},
+				Config:      testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("delete role failed|HTTP status: 403"),
+			},
+			{
+				Config:   testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.failNext(http.MethodGet, "/authorize/identity/Role/", http.StatusServiceUnavailable, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				Check:  testCheckFakeExists(f, "Role", "hsdp_iam_role.test"),
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleTokenRefresh(t *testing.T) {
+	f := newFakeHSDP(t)
+	// The token gets revoked halfway, the request should be replayed with a new one
+	f.failNext(http.MethodGet, "/authorize/identity/Role/", http.StatusUnauthorized, 1)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMRoleConfig(f, "Test role", "GROUP.READ"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Role", "hsdp_iam_role.test"),
+					func(s *terraform.State) error {
+						f.Lock()
+						defer f.Unlock()
+						if f.tokens < 2 {
+							return fmt.Errorf("expected token to be renewed, %d tokens issued", f.tokens)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleCredentials(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.providerConfig() + `
+resource "hsdp_iam_role" "test" {
+  name                  = "TENANT_ROLE"
+  description           = "Tenant role"
+  managing_organization = "fake-tenant-org"
+  permissions           = ["GROUP.READ"]
+
+  credentials {
+    username = "tenant-admin"
+    password = "tenant-password"
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "Role", "hsdp_iam_role.test"),
+					resource.TestCheckResourceAttr("hsdp_iam_role.test", "credentials.0.username", "tenant-admin"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceIAMRoleUnknownPermission(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testIAMRoleConfig(f, "Test role", "GROUP.READ", "GROUP.RAED"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"GROUP.RAED" is not a known IAM permission, did you mean GROUP.READ\?`),
+			},
+		},
+	})
+	assert.Equal(t, 0, f.count("Role"))
+}
+
+func TestPermissionsNotValidated(t *testing.T) {
+	config := &Config{}
+	config.permissionsOnce.Do(func() {
+		config.permissionsErr = errors.New("403 Forbidden")
+	})
+	diags := permissionsNotValidated(config)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Warning, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, "403 Forbidden")
+	}
+
+	config = &Config{}
+	config.permissionsOnce.Do(func() {
+		config.permissions = []string{"GROUP.READ"}
+	})
+	assert.Empty(t, permissionsNotValidated(config))
+}
+
+func TestSuggestPermissions(t *testing.T) {
+	catalogue := []string{"GROUP.READ", "GROUP.WRITE", "ROLE.READ", "USER.READ"}
+
+	assert.Equal(t, []string{"GROUP.READ"}, suggestPermissions("GROUP.RAED", catalogue))
+	assert.Equal(t, []string{"ROLE.READ", "GROUP.READ"}, suggestPermissions("role.read", catalogue))
+	assert.Empty(t, suggestPermissions("CONTRACT.CREATE", catalogue))
+	assert.Equal(t, 2, levenshtein("GROUP.RAED", "GROUP.READ"))
+}
@@ -21,6 +21,7 @@ func resourceIAMService() *schema.Resource {
 		DeleteContext: resourceIAMServiceDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"name": {
 				Type:             schema.TypeString,
 				Required:         true,
@@ -82,7 +83,7 @@ func resourceIAMServiceCreate(_ context.Context, d *schema.ResourceData, m inter
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -95,9 +96,9 @@ func resourceIAMServiceCreate(_ context.Context, d *schema.ResourceData, m inter
 	scopes := expandStringList(d.Get("scopes").(*schema.Set).List())
 	defaultScopes := expandStringList(d.Get("default_scopes").(*schema.Set).List())
 
-	createdService, _, err := client.Services.CreateService(s)
+	createdService, resp, err := client.Services.CreateService(s)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(createdService.ID)
 	_ = d.Set("expires_on", createdService.ExpiresOn)
@@ -109,9 +110,9 @@ func resourceIAMServiceCreate(_ context.Context, d *schema.ResourceData, m inter
 	_ = d.Set("description", s.Description) // RITM0021326
 
 	// Set scopes and default_scopes
-	_, _, err = client.Services.AddScopes(*createdService, scopes, defaultScopes)
+	_, resp, err = client.Services.AddScopes(*createdService, scopes, defaultScopes)
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, diagFromResponse(err, resp, "scopes")...)
 	}
 	return diags
 }
@@ -121,20 +122,26 @@ func resourceIAMServiceRead(_ context.Context, d *schema.ResourceData, m interfa
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	id := d.Id()
-	s, resp, err := client.Services.GetServiceByID(id)
+	// GetServiceByID panics on an empty search, which is what a deleted service gives
+	services, resp, err := client.Services.GetServices(&iam.GetServiceOptions{ID: &id})
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			d.SetId("")
 			return diags
 		}
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
+	if len(*services) == 0 {
+		d.SetId("")
+		return diags
+	}
+	s := (*services)[0]
 	// Until RITM0021326 is implemented, this will always clear the field
 	// d.Set("description", s.Description)
 
@@ -155,7 +162,7 @@ func resourceIAMServiceUpdate(_ context.Context, d *schema.ResourceData, m inter
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -170,13 +177,16 @@ func resourceIAMServiceUpdate(_ context.Context, d *schema.ResourceData, m inter
 		toAdd := difference(newList, old)
 		toRemove := difference(old, newList)
 		if len(toRemove) > 0 {
-			_, _, err := client.Services.RemoveScopes(s, toRemove, []string{})
+			_, resp, err := client.Services.RemoveScopes(s, toRemove, []string{})
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp, "scopes")
 			}
 		}
 		if len(toAdd) > 0 {
-			_, _, _ = client.Services.AddScopes(s, toAdd, []string{})
+			_, resp, err := client.Services.AddScopes(s, toAdd, []string{})
+			if err != nil {
+				return diagFromResponse(err, resp, "scopes")
+			}
 		}
 	}
 	if d.HasChange("default_scopes") {
@@ -186,13 +196,16 @@ func resourceIAMServiceUpdate(_ context.Context, d *schema.ResourceData, m inter
 		toAdd := difference(newList, old)
 		toRemove := difference(old, newList)
 		if len(toRemove) > 0 {
-			_, _, err := client.Services.RemoveScopes(s, []string{}, toRemove)
+			_, resp, err := client.Services.RemoveScopes(s, []string{}, toRemove)
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromResponse(err, resp, "default_scopes")
 			}
 		}
 		if len(toAdd) > 0 {
-			_, _, _ = client.Services.AddScopes(s, []string{}, toAdd)
+			_, resp, err := client.Services.AddScopes(s, []string{}, toAdd)
+			if err != nil {
+				return diagFromResponse(err, resp, "default_scopes")
+			}
 		}
 	}
 	return diags
@@ -203,16 +216,16 @@ func resourceIAMServiceDelete(_ context.Context, d *schema.ResourceData, m inter
 
 	var diags diag.Diagnostics
 
-	client, err := config.IAMClient()
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	var s iam.Service
 	s.ID = d.Id()
-	ok, _, err := client.Services.DeleteService(s)
+	ok, resp, err := client.Services.DeleteService(s)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if !ok {
 		return diag.FromErr(ErrDeleteServiceFailed)
@@ -0,0 +1,128 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testIAMServiceScopesConfig(f *fakeHSDP, applicationID string, scopes ...string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_service" "test" {
+  name           = "TESTSERVICE"
+  description    = "Test service"
+  application_id = %q
+  scopes         = %s
+  default_scopes = ["openid"]
+}
+`, applicationID, hclStringList(scopes))
+}
+
+// testFakeApplication stores an application in a proposition of fake-root-org
+func testFakeApplication(f *fakeHSDP) string {
+	return f.put("Application", fakeObject{
+		"name":          "TESTAPPLICATION",
+		"propositionId": testFakeProposition(f),
+	})
+}
+
+func testCheckFakeServiceScopes(f *fakeHSDP, scopes ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		service := f.get("Service", s.RootModule().Resources["hsdp_iam_service.test"].Primary.ID)
+		if service == nil {
+			return fmt.Errorf("service does not exist in fake")
+		}
+		current, _ := service["scopes"].([]interface{})
+		have := expandStringList(current)
+		if len(have) != len(scopes) || len(difference(scopes, have)) > 0 {
+			return fmt.Errorf("expected scopes %v, found %v", scopes, have)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMService(t *testing.T) {
+	f := newFakeHSDP(t)
+	applicationID := testFakeApplication(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			if n := f.count("Service"); n != 0 {
+				return fmt.Errorf("%d services left behind", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMServiceScopesConfig(f, applicationID, "openid", "mail"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeServiceScopes(f, "openid", "mail"),
+					resource.TestCheckResourceAttr("hsdp_iam_service.test", "organization_id", "fake-root-org"),
+					resource.TestCheckResourceAttr("hsdp_iam_service.test", "service_id",
+						"testservice@"+applicationID+".fake.example.com"),
+					resource.TestCheckResourceAttrSet("hsdp_iam_service.test", "expires_on"),
+				),
+			},
+			{
+				// Scopes are added and removed one by one, unlike those of clients
+				Config: testIAMServiceScopesConfig(f, applicationID, "openid", "cn"),
+				Check:  testCheckFakeServiceScopes(f, "openid", "cn"),
+			},
+			{
+				ResourceName:            "hsdp_iam_service.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"description", "private_key", "validity"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMServiceDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	applicationID := testFakeApplication(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMServiceScopesConfig(f, applicationID, "openid"),
+				Check:  testSaveID("hsdp_iam_service.test", &id),
+			},
+			{
+				// The ID search comes back empty for a deleted service, which
+				// GetServiceByID can not handle
+				PreConfig: func() {
+					f.remove("Service", id)
+				},
+				Config: testIAMServiceScopesConfig(f, applicationID, "openid"),
+				Check:  testCheckFakeRecreated(f, "Service", "hsdp_iam_service.test", &id),
+			},
+		},
+	})
+}
+
+func TestResourceIAMServiceRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	applicationID := testFakeApplication(f)
+	// Setting the scopes is a PUT and may be retried, the create is not
+	f.failNext(http.MethodPut, "/authorize/identity/Service/", http.StatusServiceUnavailable, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMServiceScopesConfig(f, applicationID, "openid", "mail"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeServiceScopes(f, "openid", "mail"),
+					testCheckFakeCount(f, "Service", 1),
+				),
+			},
+		},
+	})
+}
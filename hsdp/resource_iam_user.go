@@ -1,24 +1,38 @@
 package hsdp
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/philips-software/go-hsdp-api/iam"
 )
 
+const (
+	deletionPolicyDelete  = "delete"
+	deletionPolicyDisable = "disable"
+	deletionPolicyAbandon = "abandon"
+)
+
 func resourceIAMUser() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Create: resourceIAMUserCreate,
-		Read:   resourceIAMUserRead,
-		Update: resourceIAMUserUpdate,
-		Delete: resourceIAMUserDelete,
+		CreateContext: resourceIAMUserCreate,
+		ReadContext:   resourceIAMUserRead,
+		UpdateContext: resourceIAMUserUpdate,
+		DeleteContext: resourceIAMUserDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"username": &schema.Schema{
 				Type:       schema.TypeString,
 				Optional:   true,
@@ -48,50 +62,63 @@ func resourceIAMUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"disabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"deletion_policy": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  deletionPolicyDelete,
+				ValidateFunc: validation.StringInSlice([]string{
+					deletionPolicyDelete, deletionPolicyDisable, deletionPolicyAbandon}, false),
+			},
+			"account_status": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"locked": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"email_verified": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"activation_pending": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
-func resourceIAMUserCreate(d *schema.ResourceData, m interface{}) error {
-	config := m.(*Config)
-	client, err := config.IAMClient()
-	if err != nil {
-		return err
-	}
-
-	last := d.Get("last_name").(string)
-	first := d.Get("first_name").(string)
-	email := d.Get("username").(string) // Deprecated
-	mobile := d.Get("mobile").(string)
+// personFromResourceData builds the IAM Person of the user from its arguments
+func personFromResourceData(d *schema.ResourceData) iam.Person {
 	login := d.Get("login").(string)
 	if login == "" {
-		login = email
+		login = d.Get("username").(string) // Deprecated
 	}
-	email = d.Get("email").(string)
-	organization := d.Get("organization_id").(string)
+	return newPerson(login, d.Get("email").(string), d.Get("first_name").(string),
+		d.Get("last_name").(string), d.Get("mobile").(string), d.Get("organization_id").(string))
+}
 
-	// First check if this user already exists
-	uuid, _, err := client.Users.GetUserIDByLoginID(email)
-	if err == nil && uuid != "" {
-		user, _, _ := client.Users.GetUserByID(uuid)
-		if user != nil {
-			if user.AccountStatus.Disabled {
-				// Retrigger activation email
-				_, _, err = client.Users.ResendActivation(email)
-				return err
-			}
-			err = resourceIAMUserRead(d, m)
-			if err == nil {
-				d.SetId(user.ID)
-			}
-			return nil
-		}
-	}
+// newPerson builds the IAM Person of a user in organization orgID
+func newPerson(login, email, firstName, lastName, mobile, orgID string) iam.Person {
 	person := iam.Person{
 		ResourceType: "Person",
 		Name: iam.Name{
-			Family: last,
-			Given:  first,
+			Family: lastName,
+			Given:  firstName,
 		},
 		LoginID: login,
 		Telecom: []iam.TelecomEntry{
@@ -100,7 +127,7 @@ func resourceIAMUserCreate(d *schema.ResourceData, m interface{}) error {
 				Value:  email,
 			},
 		},
-		ManagingOrganization: organization,
+		ManagingOrganization: orgID,
 		IsAgeValidated:       "true",
 	}
 	if mobile != "" {
@@ -110,48 +137,202 @@ func resourceIAMUserCreate(d *schema.ResourceData, m interface{}) error {
 				Value:  mobile,
 			})
 	}
-	user, _, err := client.Users.CreateUser(person)
+	return person
+}
+
+// iamUser is a user as returned by the IAM User API. The User of go-hsdp-api
+// leaves out the telecom entries which hold the mobile number
+type iamUser struct {
+	iam.User
+	Telecom []iam.TelecomEntry `json:"telecom"`
+}
+
+// getUser looks up user id with its full profile. A user which does not
+// exist is returned as nil
+func (c *Config) getUser(ctx context.Context, client *iam.Client, id string) (*iamUser, *http.Response, error) {
+	query := url.Values{
+		"userId":      {id},
+		"profileType": {"all"},
+	}
+	var bundle struct {
+		Entry []iamUser `json:"entry"`
+	}
+	resp, err := c.iamRequestVersion(ctx, client, "2", http.MethodGet, "/authorize/identity/User?"+query.Encode(), nil, &bundle)
 	if err != nil {
-		return err
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, resp, nil
+		}
+		return nil, resp, err
 	}
-	if user == nil {
-		return fmt.Errorf("Error creating user")
+	if len(bundle.Entry) == 0 {
+		return nil, resp, nil
+	}
+	return &bundle.Entry[0], resp, nil
+}
+
+// updateUser replaces the profile of person through the IAM Person API, which
+// go-hsdp-api has no call for
+func (c *Config) updateUser(ctx context.Context, client *iam.Client, person iam.Person) (*http.Response, error) {
+	return c.iamRequestVersion(ctx, client, "2", http.MethodPut, "/authorize/identity/User/"+person.ID, person, nil)
+}
+
+// existingUser returns the user with loginID, or nil when there is none. Failed
+// lookups are returned as errors, otherwise a transient error would lead to a
+// duplicate user being created
+func (c *Config) existingUser(ctx context.Context, client *iam.Client, loginID string) (*iamUser, *http.Response, error) {
+	uuid, resp, err := client.Users.GetUserIDByLoginID(loginID)
+	if err != nil {
+		if resp == nil || resp.Response == nil {
+			return nil, nil, err
+		}
+		// An empty result is reported as an error of a successful lookup
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+			return nil, nil, nil
+		}
+		return nil, resp.Response, err
+	}
+	if uuid == "" {
+		return nil, nil, nil
+	}
+	return c.getUser(ctx, client, uuid)
+}
+
+// userDeactivated reports whether the account of user was disabled after it
+// was activated, as opposed to an account which awaits activation
+func userDeactivated(user *iamUser) bool {
+	return user.AccountStatus.Disabled && user.AccountStatus.EmailVerified
+}
+
+// setUserActive activates or deactivates the account of user id. The account
+// and its audit trail are kept. go-hsdp-api has no call for this so the SCIM
+// Users endpoint is patched directly
+func (c *Config) setUserActive(ctx context.Context, client *iam.Client, id string, active bool) (*http.Response, error) {
+	patch := map[string]interface{}{
+		"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": []map[string]interface{}{
+			{"op": "replace", "path": "active", "value": active},
+		},
+	}
+	return c.iamRequestVersion(ctx, client, "2", http.MethodPatch, "/authorize/scim/v2/Users/"+id, patch, nil)
+}
+
+// mobileNumber returns the mobile phone number of the user, if any
+func mobileNumber(user *iamUser) string {
+	for _, entry := range user.Telecom {
+		if strings.EqualFold(entry.System, "mobile") {
+			return entry.Value
+		}
+	}
+	return ""
+}
+
+// accountStatus flattens the account status of the user. An account which was
+// never activated stays disabled with an unverified email address
+func accountStatus(user *iamUser) []interface{} {
+	status := user.AccountStatus
+	locked := status.AccountLockedUntil.After(time.Now())
+	return []interface{}{
+		map[string]interface{}{
+			"disabled":           status.Disabled,
+			"locked":             locked,
+			"email_verified":     status.EmailVerified,
+			"activation_pending": status.Disabled && !status.EmailVerified,
+		},
 	}
-	d.SetId(user.ID)
-	return nil
 }
 
-func resourceIAMUserRead(d *schema.ResourceData, m interface{}) error {
+func resourceIAMUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
-	client, err := config.IAMClient()
+	ctx = withResource(ctx, "hsdp_iam_user", d.Id())
+
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	id := d.Id()
+	person := personFromResourceData(d)
+	email := d.Get("email").(string)
 
-	user, _, err := client.Users.GetUserByID(id)
+	// First check if this user already exists
+	user, httpResp, err := config.existingUser(ctx, client, email)
+	if err != nil {
+		return diagFromResponse(err, httpResp)
+	}
+	disabled := d.Get("disabled").(bool)
+	if user != nil {
+		switch {
+		case userDeactivated(user) != disabled:
+			httpResp, err := config.setUserActive(ctx, client, user.ID, !disabled)
+			if err != nil {
+				return diagFromResponse(err, httpResp, "disabled")
+			}
+		case user.AccountStatus.Disabled && !disabled:
+			// Retrigger activation email
+			_, resp, err := client.Users.ResendActivation(email)
+			if err != nil {
+				return diagFromResponse(err, resp)
+			}
+		}
+		d.SetId(user.ID)
+		return resourceIAMUserRead(ctx, d, m)
+	}
+	createdUser, resp, err := client.Users.CreateUser(person)
 	if err != nil {
-		if _, ok := err.(*iam.UserError); ok {
-			d.SetId("")
-			return nil
+		return diagFromResponse(err, resp)
+	}
+	if createdUser == nil {
+		return diag.FromErr(fmt.Errorf("Error creating user"))
+	}
+	d.SetId(createdUser.ID)
+	if disabled {
+		httpResp, err := config.setUserActive(ctx, client, createdUser.ID, false)
+		if err != nil {
+			return diagFromResponse(err, httpResp, "disabled")
 		}
-		return err
+	}
+	return resourceIAMUserRead(ctx, d, m)
+}
+
+func resourceIAMUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	id := d.Id()
+
+	user, resp, err := config.getUser(ctx, client, id)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
+	if user == nil {
+		d.SetId("")
+		return diags
 	}
 	_ = d.Set("login", user.LoginID)
 	_ = d.Set("last_name", user.Name.Family)
 	_ = d.Set("first_name", user.Name.Given)
 	_ = d.Set("email", user.EmailAddress)
-	_ = d.Set("login", user.LoginID)
+	_ = d.Set("mobile", mobileNumber(user))
 	_ = d.Set("organization_id", user.ManagingOrganization)
-	return nil
+	// A user disabled before activation cannot be told apart from a pending activation
+	disabled := userDeactivated(user) || (d.Get("disabled").(bool) && user.AccountStatus.Disabled)
+	_ = d.Set("disabled", disabled)
+	_ = d.Set("account_status", accountStatus(user))
+	return diags
 }
 
-func resourceIAMUserUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceIAMUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
-	client, err := config.IAMClient()
+	ctx = withResource(ctx, "hsdp_iam_user", d.Id())
+
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	var p iam.Person
@@ -159,39 +340,73 @@ func resourceIAMUserUpdate(d *schema.ResourceData, m interface{}) error {
 
 	if d.HasChange("login") {
 		newLogin := d.Get("login").(string)
-		_, _, err := client.Users.ChangeLoginID(p, newLogin)
+		_, resp, err := client.Users.ChangeLoginID(p, newLogin)
+		if err != nil {
+			return diagFromResponse(err, resp, "login")
+		}
+	}
+	if d.HasChanges("email", "first_name", "last_name", "mobile") {
+		person := personFromResourceData(d)
+		person.ID = d.Id()
+		resp, err := config.updateUser(ctx, client, person)
+		if err != nil {
+			return diagFromResponse(err, resp)
+		}
+	}
+	if d.HasChange("disabled") {
+		resp, err := config.setUserActive(ctx, client, d.Id(), !d.Get("disabled").(bool))
 		if err != nil {
-			return err
+			return diagFromResponse(err, resp, "disabled")
 		}
 	}
-	return nil
+	return resourceIAMUserRead(ctx, d, m)
 }
 
-func resourceIAMUserDelete(d *schema.ResourceData, m interface{}) error {
+func resourceIAMUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
-	client, err := config.IAMClient()
+	ctx = withResource(ctx, "hsdp_iam_user", d.Id())
+
+	var diags diag.Diagnostics
+
+	if d.Get("deletion_policy").(string) == deletionPolicyAbandon {
+		d.SetId("")
+		return diags
+	}
+
+	client, err := config.IAMClient(principalFromResourceData(d))
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	id := d.Id()
 
-	user, _, err := client.Users.GetUserByID(id)
+	user, httpResp, err := config.getUser(ctx, client, id)
 	if err != nil {
-		if _, ok := err.(*iam.UserError); ok {
-			d.SetId("")
-			return nil
-		}
-		return err
+		return diagFromResponse(err, httpResp)
 	}
 	if user == nil {
-		return nil
+		d.SetId("")
+		return diags
+	}
+	// Keep the account and its audit trail, but prevent logins
+	if d.Get("deletion_policy").(string) == deletionPolicyDisable {
+		if !user.AccountStatus.Disabled {
+			httpResp, err = config.setUserActive(ctx, client, user.ID, false)
+			if err != nil {
+				return diagFromResponse(err, httpResp)
+			}
+		}
+		d.SetId("")
+		return diags
 	}
 	var person iam.Person
 	person.ID = user.ID
-	ok, _, _ := client.Users.DeleteUser(person)
+	ok, resp, err := client.Users.DeleteUser(person)
+	if err != nil {
+		return diagFromResponse(err, resp)
+	}
 	if ok {
 		d.SetId("")
 	}
-	return nil
+	return diags
 }
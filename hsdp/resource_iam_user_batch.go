@@ -0,0 +1,547 @@
+package hsdp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/philips-software/go-hsdp-api/iam"
+)
+
+const (
+	userBatchCreated     = "created"
+	userBatchExisting    = "existing"
+	userBatchUpdated     = "updated"
+	userBatchUnchanged   = "unchanged"
+	userBatchDeactivated = "deactivated"
+	userBatchMissing     = "missing"
+	userBatchFailed      = "failed"
+)
+
+// userRecord is a single user of a hsdp_iam_user_batch
+type userRecord struct {
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Mobile    string `json:"mobile"`
+}
+
+// checksum identifies the contents of the record so unchanged users are skipped
+func (r userRecord) checksum() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{r.Login, r.Email, r.FirstName, r.LastName, r.Mobile}, "\x00")))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// userBatchStatus is the outcome of reconciling a single user
+type userBatchStatus struct {
+	Login    string
+	UserID   string
+	Status   string
+	Error    string
+	Checksum string
+}
+
+func resourceIAMUserBatch() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIAMUserBatchCreate,
+		ReadContext:   resourceIAMUserBatchRead,
+		UpdateContext: resourceIAMUserBatchUpdate,
+		DeleteContext: resourceIAMUserBatchDelete,
+		CustomizeDiff: customizeUserBatchRetry,
+
+		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"organization_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"user": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"csv", "json"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"login": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"email": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"first_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"last_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"mobile": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"csv": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"user", "json"},
+			},
+			"json": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"user", "csv"},
+			},
+			"adopt_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4,
+				ValidateFunc: validation.IntBetween(1, 16),
+			},
+			"status": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"login": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"error": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"checksum": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// parseUserRecordsCSV reads user records from CSV with a header row naming
+// the login, email, first_name, last_name and mobile columns
+func parseUserRecordsCSV(data string) ([]userRecord, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var records []userRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		records = append(records, userRecord{
+			Login:     field(row, "login"),
+			Email:     field(row, "email"),
+			FirstName: field(row, "first_name"),
+			LastName:  field(row, "last_name"),
+			Mobile:    field(row, "mobile"),
+		})
+	}
+	return records, nil
+}
+
+// userBatchRecords returns the validated user records of the batch. The login
+// defaults to the email address
+func userBatchRecords(d *schema.ResourceData) ([]userRecord, error) {
+	var records []userRecord
+	switch {
+	case d.Get("csv").(string) != "":
+		parsed, err := parseUserRecordsCSV(d.Get("csv").(string))
+		if err != nil {
+			return nil, err
+		}
+		records = parsed
+	case d.Get("json").(string) != "":
+		if err := json.Unmarshal([]byte(d.Get("json").(string)), &records); err != nil {
+			return nil, fmt.Errorf("json: %w", err)
+		}
+	default:
+		for _, u := range d.Get("user").([]interface{}) {
+			m := u.(map[string]interface{})
+			records = append(records, userRecord{
+				Login:     m["login"].(string),
+				Email:     m["email"].(string),
+				FirstName: m["first_name"].(string),
+				LastName:  m["last_name"].(string),
+				Mobile:    m["mobile"].(string),
+			})
+		}
+	}
+	seen := make(map[string]bool)
+	for i := range records {
+		if records[i].Login == "" {
+			records[i].Login = records[i].Email
+		}
+		r := records[i]
+		if r.Login == "" || r.Email == "" || r.FirstName == "" || r.LastName == "" {
+			return nil, fmt.Errorf("user %d: login or email, first_name and last_name are required", i+1)
+		}
+		if seen[r.Login] {
+			return nil, fmt.Errorf("user %d: duplicate login %s", i+1, r.Login)
+		}
+		seen[r.Login] = true
+	}
+	return records, nil
+}
+
+// userBatchStatuses returns the status of the users in state by login
+func userBatchStatuses(d *schema.ResourceData) map[string]userBatchStatus {
+	statuses := make(map[string]userBatchStatus)
+	for _, s := range d.Get("status").([]interface{}) {
+		m := s.(map[string]interface{})
+		status := userBatchStatus{
+			Login:    m["login"].(string),
+			UserID:   m["user_id"].(string),
+			Status:   m["status"].(string),
+			Error:    m["error"].(string),
+			Checksum: m["checksum"].(string),
+		}
+		statuses[status.Login] = status
+	}
+	return statuses
+}
+
+func setUserBatchStatuses(d *schema.ResourceData, statuses []userBatchStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Login < statuses[j].Login
+	})
+	list := make([]interface{}, len(statuses))
+	for i, s := range statuses {
+		list[i] = map[string]interface{}{
+			"login":    s.Login,
+			"user_id":  s.UserID,
+			"status":   s.Status,
+			"error":    s.Error,
+			"checksum": s.Checksum,
+		}
+	}
+	_ = d.Set("status", list)
+}
+
+// forEachConcurrently calls fn for 0..n-1 with at most concurrency calls in flight
+func forEachConcurrently(n, concurrency int, fn func(i int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// reconcileUser creates or updates the user of record. Users are matched on
+// login, accounts which exist outside of the batch are only adopted and
+// updated when adopt is set
+func reconcileUser(ctx context.Context, config *Config, client *iam.Client, orgID string, record userRecord, previous userBatchStatus, adopt bool) userBatchStatus {
+	status := userBatchStatus{Login: record.Login, UserID: previous.UserID, Checksum: record.checksum()}
+	failed := func(err error) userBatchStatus {
+		status.Status = userBatchFailed
+		status.Error = err.Error()
+		return status
+	}
+	if err := ctx.Err(); err != nil {
+		return failed(err)
+	}
+	switch previous.Status {
+	case userBatchCreated, userBatchExisting, userBatchUpdated, userBatchUnchanged:
+		if previous.Checksum == status.Checksum {
+			status.Status = userBatchUnchanged
+			return status
+		}
+	case userBatchMissing:
+		status.UserID = ""
+	}
+
+	person := newPerson(record.Login, record.Email, record.FirstName, record.LastName, record.Mobile, orgID)
+	if status.UserID == "" {
+		user, _, err := config.existingUser(ctx, client, record.Login)
+		if err != nil {
+			return failed(err)
+		}
+		if user == nil {
+			created, _, err := client.Users.CreateUser(person)
+			if err != nil {
+				return failed(err)
+			}
+			if created == nil {
+				return failed(fmt.Errorf("error creating user"))
+			}
+			status.UserID = created.ID
+			status.Status = userBatchCreated
+			return status
+		}
+		if !adopt {
+			return failed(fmt.Errorf("%w: %s, set adopt_existing to manage it", ErrUserAlreadyExists, record.Login))
+		}
+		status.UserID = user.ID
+		status.Status = userBatchExisting
+	} else {
+		status.Status = userBatchUpdated
+	}
+
+	// Users removed from an earlier version of the batch were deactivated
+	if previous.Status == userBatchDeactivated {
+		if _, err := config.setUserActive(ctx, client, status.UserID, true); err != nil {
+			return failed(err)
+		}
+	}
+	person.ID = status.UserID
+	if _, err := config.updateUser(ctx, client, person); err != nil {
+		return failed(err)
+	}
+	return status
+}
+
+// reconcileUserBatch creates and updates the users of the batch and
+// deactivates the users which were removed from it. Failures are recorded
+// per user and reported as warnings so the rest of the batch is applied
+func reconcileUserBatch(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_user_batch", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	records, err := userBatchRecords(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	orgID := d.Get("organization_id").(string)
+	previous := userBatchStatuses(d)
+	concurrency := d.Get("concurrency").(int)
+	adopt := d.Get("adopt_existing").(bool)
+
+	statuses := make([]userBatchStatus, len(records))
+	forEachConcurrently(len(records), concurrency, func(i int) {
+		statuses[i] = reconcileUser(ctx, config, client, orgID, records[i], previous[records[i].Login], adopt)
+	})
+
+	// Users which went missing or were deactivated by an earlier apply are
+	// no longer tracked once they are removed from the batch
+	var removed []userBatchStatus
+	for _, r := range records {
+		delete(previous, r.Login)
+	}
+	for _, p := range previous {
+		if p.UserID != "" && p.Status != userBatchMissing && p.Status != userBatchDeactivated {
+			removed = append(removed, p)
+		}
+	}
+	forEachConcurrently(len(removed), concurrency, func(i int) {
+		resp, err := config.setUserActive(ctx, client, removed[i].UserID, false)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			removed[i].Status = userBatchMissing
+			return
+		}
+		if err != nil {
+			removed[i].Error = err.Error()
+			return
+		}
+		removed[i].Status = userBatchDeactivated
+		removed[i].Error = ""
+	})
+	for _, r := range removed {
+		if r.Status != userBatchMissing {
+			statuses = append(statuses, r)
+		}
+	}
+
+	var failures []string
+	for _, s := range statuses {
+		if s.Status == userBatchFailed || s.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", s.Login, s.Error))
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%d of %d users in batch %s could not be reconciled", len(failures), len(statuses), d.Get("name").(string)),
+			Detail:   strings.Join(failures, "\n"),
+		})
+	}
+	setUserBatchStatuses(d, statuses)
+	return diags
+}
+
+// customizeUserBatchRetry plans another reconcile while users failed or went missing
+func customizeUserBatchRetry(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	for _, s := range d.Get("status").([]interface{}) {
+		m := s.(map[string]interface{})
+		if m["status"] == userBatchFailed || m["status"] == userBatchMissing || m["error"] != "" {
+			return d.SetNewComputed("status")
+		}
+	}
+	return nil
+}
+
+func resourceIAMUserBatchCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Invalid records must not leave an empty batch behind in state
+	if _, err := userBatchRecords(d); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(d.Get("organization_id").(string) + "/" + d.Get("name").(string))
+	return reconcileUserBatch(ctx, d, m)
+}
+
+func resourceIAMUserBatchUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return reconcileUserBatch(ctx, d, m)
+}
+
+func resourceIAMUserBatchRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	previous := userBatchStatuses(d)
+	statuses := make([]userBatchStatus, 0, len(previous))
+	for _, s := range previous {
+		statuses = append(statuses, s)
+	}
+
+	// Users deleted outside of Terraform are recreated by the next apply
+	errs := make([]string, len(statuses))
+	forEachConcurrently(len(statuses), d.Get("concurrency").(int), func(i int) {
+		if statuses[i].UserID == "" {
+			return
+		}
+		user, _, err := config.getUser(ctx, client, statuses[i].UserID)
+		if err != nil {
+			errs[i] = fmt.Sprintf("%s: %v", statuses[i].Login, err)
+			return
+		}
+		if user == nil {
+			statuses[i].Status = userBatchMissing
+			statuses[i].Checksum = ""
+		}
+	})
+	setUserBatchStatuses(d, statuses)
+	var failures []string
+	for _, e := range errs {
+		if e != "" {
+			failures = append(failures, e)
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "failed to read users of batch " + d.Get("name").(string),
+			Detail:   strings.Join(failures, "\n"),
+		})
+	}
+	return diags
+}
+
+func resourceIAMUserBatchDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := m.(*Config)
+	ctx = withResource(ctx, "hsdp_iam_user_batch", d.Id())
+
+	var diags diag.Diagnostics
+
+	client, err := config.IAMClient(principalFromResourceData(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	previous := userBatchStatuses(d)
+	statuses := make([]userBatchStatus, 0, len(previous))
+	for _, s := range previous {
+		statuses = append(statuses, s)
+	}
+
+	// Accounts are deactivated rather than deleted to keep their audit trail
+	errs := make([]string, len(statuses))
+	forEachConcurrently(len(statuses), d.Get("concurrency").(int), func(i int) {
+		s := statuses[i]
+		if s.UserID == "" || s.Status == userBatchDeactivated || s.Status == userBatchMissing {
+			return
+		}
+		if _, err := config.setUserActive(ctx, client, s.UserID, false); err != nil {
+			errs[i] = fmt.Sprintf("%s: %v", s.Login, err)
+		}
+	})
+	var failures []string
+	for _, e := range errs {
+		if e != "" {
+			failures = append(failures, e)
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "failed to deactivate users of batch " + d.Get("name").(string),
+			Detail:   strings.Join(failures, "\n"),
+		})
+	}
+	d.SetId("")
+	return diags
+}
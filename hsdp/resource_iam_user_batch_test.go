@@ -0,0 +1,180 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMUserBatchConfig(f *fakeHSDP, adopt bool, csv string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_user_batch" "test" {
+  name            = "hospital"
+  organization_id = "fake-root-org"
+  concurrency     = 2
+  adopt_existing  = %t
+
+  csv = <<EOF
+%sEOF
+}
+`, adopt, csv)
+}
+
+// testCheckFakeUserDisabled verifies the account of login is disabled in the fake
+func testCheckFakeUserDisabled(f *fakeHSDP, login string, disabled bool) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		id := f.find("User", "loginId", login)
+		if id == "" {
+			return fmt.Errorf("user %s does not exist in fake", login)
+		}
+		status, _ := f.get("User", id)["accountStatus"].(fakeObject)
+		if got := status != nil && status["disabled"] == true; got != disabled {
+			return fmt.Errorf("user %s disabled is %t, expected %t", login, got, disabled)
+		}
+		return nil
+	}
+}
+
+// testCheckFakeUserCount verifies the number of users in the fake
+func testCheckFakeUserCount(f *fakeHSDP, count int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if got := f.count("User"); got != count {
+			return fmt.Errorf("expected %d users in fake, got %d", count, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMUserBatch(t *testing.T) {
+	f := newFakeHSDP(t)
+	carolID := f.put("User", fakeObject{
+		"loginId":              "carol@example.com",
+		"managingOrganization": "fake-root-org",
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMUserBatchConfig(f, true, `login,email,first_name,last_name
+alice@example.com,alice@example.com,Alice,Smith
+bob@example.com,bob@example.com,Bob,Jones
+carol@example.com,carol@example.com,Carol,White
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.#", "3"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.login", "alice@example.com"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.status", userBatchCreated),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.2.status", userBatchExisting),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.2.user_id", carolID),
+				),
+			},
+			{
+				Config: testIAMUserBatchConfig(f, true, `login,email,first_name,last_name
+alice@example.com,alice@example.com,Alice,Smith-Brown
+carol@example.com,carol@example.com,Carol,White
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.#", "3"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.status", userBatchUpdated),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.1.login", "bob@example.com"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.1.status", userBatchDeactivated),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.2.status", userBatchUnchanged),
+					testCheckFakeUserDisabled(f, "bob@example.com", true),
+				),
+			},
+			{
+				// Alice is deleted outside of Terraform and removed from the batch,
+				// Bob was deactivated before. Neither is tracked any longer
+				PreConfig: func() {
+					f.remove("User", f.find("User", "loginId", "alice@example.com"))
+				},
+				Config: testIAMUserBatchConfig(f, true, `login,email,first_name,last_name
+carol@example.com,carol@example.com,Carol,White
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.#", "1"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.login", "carol@example.com"),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.error", ""),
+				),
+			},
+			{
+				Config: testIAMUserBatchConfig(f, true, `login,email,first_name,last_name
+carol@example.com,carol@example.com,Carol,White
+`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestResourceIAMUserBatchExistingUsers(t *testing.T) {
+	f := newFakeHSDP(t)
+	f.put("User", fakeObject{
+		"loginId":              "carol@example.com",
+		"managingOrganization": "fake-root-org",
+	})
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testIAMUserBatchConfig(f, false, "login,email,first_name,last_name\nbob@example.com,bob@example.com,Bob\n"),
+				ExpectError: regexp.MustCompile("wrong number of fields"),
+			},
+			{
+				PreConfig: func() {
+					f.failNext(http.MethodGet, "/authorize/identity/User", http.StatusInternalServerError, 1)
+				},
+				Config: testIAMUserBatchConfig(f, false, `login,email,first_name,last_name
+alice@example.com,alice@example.com,Alice,Smith
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.status", userBatchFailed),
+					testCheckFakeUserCount(f, 1),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testIAMUserBatchConfig(f, false, `login,email,first_name,last_name
+alice@example.com,alice@example.com,Alice,Smith
+carol@example.com,carol@example.com,Carol,White
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.0.status", userBatchCreated),
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.1.status", userBatchFailed),
+					resource.TestMatchResourceAttr("hsdp_iam_user_batch.test", "status.1.error", regexp.MustCompile("adopt_existing")),
+					testCheckFakeUserCount(f, 2),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testIAMUserBatchConfig(f, true, `login,email,first_name,last_name
+alice@example.com,alice@example.com,Alice,Smith
+carol@example.com,carol@example.com,Carol,White
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user_batch.test", "status.1.status", userBatchExisting),
+					testCheckFakeUserCount(f, 2),
+				),
+			},
+		},
+	})
+}
+
+func TestParseUserRecordsCSV(t *testing.T) {
+	records, err := parseUserRecordsCSV("email, first_name, last_name, mobile\njane@example.com, Jane, Doe, +31600000000\n")
+	if !assert.Nil(t, err) || !assert.Len(t, records, 1) {
+		return
+	}
+	assert.Equal(t, "jane@example.com", records[0].Email)
+	assert.Equal(t, "", records[0].Login)
+	assert.Equal(t, "+31600000000", records[0].Mobile)
+
+	_, err = parseUserRecordsCSV("")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,256 @@
+package hsdp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func testIAMUserConfig(f *fakeHSDP, disabled bool) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_user" "test" {
+  login           = "leslie@example.com"
+  email           = "leslie@example.com"
+  first_name      = "Leslie"
+  last_name       = "Knope"
+  organization_id = "fake-root-org"
+  disabled        = %t
+  deletion_policy = "disable"
+}
+`, disabled)
+}
+
+func testIAMUserProfileConfig(f *fakeHSDP, firstName, mobile string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_iam_user" "test" {
+  login           = "ann@example.com"
+  email           = "ann@example.com"
+  first_name      = %q
+  last_name       = "Perkins"
+  mobile          = %q
+  organization_id = "fake-root-org"
+}
+`, firstName, mobile)
+}
+
+// testCheckFakeUserProfile verifies the given name and mobile number of login in the fake
+func testCheckFakeUserProfile(f *fakeHSDP, login, firstName, mobile string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		id := f.find("User", "loginId", login)
+		if id == "" {
+			return fmt.Errorf("user %s does not exist in fake", login)
+		}
+		user := f.get("User", id)
+		name, _ := user["name"].(map[string]interface{})
+		if name["given"] != firstName {
+			return fmt.Errorf("user %s has first name %v, expected %s", login, name["given"], firstName)
+		}
+		var got string
+		telecom, _ := user["telecom"].([]interface{})
+		for _, t := range telecom {
+			if entry, ok := t.(map[string]interface{}); ok && entry["system"] == "mobile" {
+				got, _ = entry["value"].(string)
+			}
+		}
+		if got != mobile {
+			return fmt.Errorf("user %s has mobile %q, expected %q", login, got, mobile)
+		}
+		return nil
+	}
+}
+
+func TestResourceIAMUserUpdateProfile(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMUserProfileConfig(f, "Ann", "+15550100"),
+				Check: resource.ComposeTestCheckFunc(
+					testSaveID("hsdp_iam_user.test", &id),
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "mobile", "+15550100"),
+				),
+			},
+			{
+				// The profile is replaced in place through the v2 User API,
+				// which the fake only serves with Api-Version 2
+				Config: testIAMUserProfileConfig(f, "Anne", "+15550199"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("hsdp_iam_user.test", "id", &id),
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "first_name", "Anne"),
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "mobile", "+15550199"),
+					testCheckFakeUserProfile(f, "ann@example.com", "Anne", "+15550199"),
+				),
+			},
+			{
+				// A mobile number changed outside Terraform shows up as drift
+				PreConfig: func() {
+					f.update("User", id, fakeObject{"telecom": []interface{}{
+						map[string]interface{}{"system": "email", "value": "ann@example.com"},
+						map[string]interface{}{"system": "mobile", "value": "+15550111"},
+					}})
+				},
+				Config:             testIAMUserProfileConfig(f, "Anne", "+15550199"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testIAMUserProfileConfig(f, "Anne", "+15550199"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("hsdp_iam_user.test", "id", &id),
+					testCheckFakeUserProfile(f, "ann@example.com", "Anne", "+15550199"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccountStatus(t *testing.T) {
+	user := &iamUser{}
+	user.AccountStatus.Disabled = true
+	assert.Equal(t, map[string]interface{}{
+		"disabled":           true,
+		"locked":             false,
+		"email_verified":     false,
+		"activation_pending": true,
+	}, accountStatus(user)[0])
+
+	user.AccountStatus.EmailVerified = true
+	user.AccountStatus.AccountLockedUntil = time.Now().Add(time.Hour)
+	assert.Equal(t, map[string]interface{}{
+		"disabled":           true,
+		"locked":             true,
+		"email_verified":     true,
+		"activation_pending": false,
+	}, accountStatus(user)[0])
+
+	// A lock which has expired no longer counts
+	user.AccountStatus.Disabled = false
+	user.AccountStatus.AccountLockedUntil = time.Now().Add(-time.Hour)
+	assert.Equal(t, map[string]interface{}{
+		"disabled":           false,
+		"locked":             false,
+		"email_verified":     true,
+		"activation_pending": false,
+	}, accountStatus(user)[0])
+}
+
+func TestResourceIAMUserDisable(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(_ *terraform.State) error {
+			// The disable deletion policy keeps the account
+			return testCheckFakeUserDisabled(f, "leslie@example.com", true)(nil)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMUserConfig(f, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "disabled", "false"),
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "account_status.0.activation_pending", "true"),
+				),
+			},
+			{
+				Config: testIAMUserConfig(f, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "disabled", "true"),
+					testCheckFakeUserDisabled(f, "leslie@example.com", true),
+				),
+			},
+			{
+				Config: testIAMUserConfig(f, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_iam_user.test", "disabled", "false"),
+					testCheckFakeUserDisabled(f, "leslie@example.com", false),
+				),
+			},
+		},
+	})
+}
+
+func TestSetUserActive(t *testing.T) {
+	f := newFakeHSDP(t)
+	id := f.put("User", fakeObject{
+		"loginId":              "leslie@example.com",
+		"managingOrganization": "fake-root-org",
+		"accountStatus":        fakeObject{"disabled": false, "emailVerified": true},
+	})
+	config := f.config()
+	client, err := config.IAMClient()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The fake only serves the SCIM Users endpoint with API version 2
+	_, err = config.setUserActive(context.Background(), client, id, false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, testCheckFakeUserDisabled(f, "leslie@example.com", true)(nil))
+
+	_, err = config.setUserActive(context.Background(), client, id, true)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, testCheckFakeUserDisabled(f, "leslie@example.com", false)(nil))
+}
+
+func TestResourceIAMUserDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMUserConfig(f, false),
+				Check:  testSaveID("hsdp_iam_user.test", &id),
+			},
+			{
+				// A deleted user is no longer found by the userId search and is
+				// invited again under a new UUID
+				PreConfig: func() {
+					f.remove("User", id)
+				},
+				Config: testIAMUserConfig(f, false),
+				Check:  testCheckFakeRecreated(f, "User", "hsdp_iam_user.test", &id),
+			},
+			{
+				ResourceName:            "hsdp_iam_user.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_policy", "username"},
+			},
+		},
+	})
+}
+
+func TestResourceIAMUserRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+	// Both the search for an existing login and the read of the created user
+	// hit the user search
+	f.failNext(http.MethodGet, "/authorize/identity/User", http.StatusServiceUnavailable, 2)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testIAMUserConfig(f, false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "User", "hsdp_iam_user.test"),
+					testCheckFakeCount(f, "User", 1),
+				),
+			},
+		},
+	})
+}
@@ -3,18 +3,19 @@ package hsdp
 import (
 	"context"
 	"fmt"
-	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/philips-software/go-hsdp-api/console"
 	"net/http"
-	"strings"
 	"time"
 )
 
 func resourceMetricsAutoscaler() *schema.Resource {
 	return &schema.Resource{
+		Importer: &schema.ResourceImporter{
+			StateContext: importMetricsAutoscalerContext,
+		},
 		CreateContext: resourceMetricsAutoscalerCreate,
 		ReadContext:   resourceMetricsAutoscalerRead,
 		UpdateContext: resourceMetricsAutoscalerUpdate,
@@ -172,9 +173,9 @@ func resourceMetricsAutoscalerDelete(ctx context.Context, d *schema.ResourceData
 	instanceID := d.Get("metrics_instance_id").(string)
 	app.Name = d.Get("app_name").(string)
 	app.Enabled = false
-	result, err := updateWithRetry(client, instanceID, app)
+	result, resp, err := client.Metrics.UpdateApplicationAutoscaler(instanceID, app)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if result == nil {
 		return diag.FromErr(fmt.Errorf("error creating/updating autoscaler"))
@@ -199,9 +200,13 @@ func resourceMetricsAutoscalerRead(ctx context.Context, d *schema.ResourceData,
 	instanceID := d.Get("metrics_instance_id").(string)
 	name := d.Get("app_name").(string)
 
-	app, err := getWitRetry(client, instanceID, name)
+	app, resp, err := client.Metrics.GetApplicationAutoscaler(instanceID, name)
 	if err != nil {
-		return diag.FromErr(err)
+		if resp != nil && resp.StatusCode == http.StatusNotFound { // App or metrics instance is gone
+			d.SetId("")
+			return diags
+		}
+		return diagFromResponse(err, resp)
 	}
 	_ = d.Set("min_instances", app.MinInstances)
 	_ = d.Set("max_instances", app.MaxInstances)
@@ -226,6 +231,19 @@ func resourceMetricsThresholdHash(v interface{}) int {
 	return 0
 }
 
+// importMetricsAutoscalerContext imports the autoscaler of an app by
+// metrics_instance_id,app_name
+func importMetricsAutoscalerContext(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	result, err := importStateWithFields("metrics_instance_id")(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	instanceID := d.Get("metrics_instance_id").(string)
+	_ = d.Set("app_name", d.Id())
+	d.SetId(instanceID + d.Id())
+	return result, nil
+}
+
 func resourceMetricsAutoscalerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
 
@@ -258,9 +276,9 @@ func resourceMetricsAutoscalerCreate(ctx context.Context, d *schema.ResourceData
 			}
 		}
 	}
-	created, err := updateWithRetry(client, instanceID, app)
+	created, resp, err := client.Metrics.UpdateApplicationAutoscaler(instanceID, app)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if created == nil {
 		return diag.FromErr(fmt.Errorf("error creating/updating autoscaler"))
@@ -268,41 +286,3 @@ func resourceMetricsAutoscalerCreate(ctx context.Context, d *schema.ResourceData
 	d.SetId(instanceID + created.Name)
 	return diags
 }
-
-func updateWithRetry(client *console.Client, instanceID string, app console.Application) (*console.Application, error) {
-	var created *console.Application
-	operation := func() error {
-		var err error
-		var resp *console.Response
-		created, resp, err = client.Metrics.UpdateApplicationAutoscaler(instanceID, app)
-		return checkForIntermittentErrors(resp, err)
-	}
-	err := backoff.Retry(operation, backoff.NewExponentialBackOff())
-	return created, err
-}
-
-func getWitRetry(client *console.Client, instanceID string, name string) (*console.Application, error) {
-	var app *console.Application
-	operation := func() error {
-		var err error
-		var resp *console.Response
-		app, resp, err = client.Metrics.GetApplicationAutoscaler(instanceID, name)
-		return checkForIntermittentErrors(resp, err)
-	}
-	err := backoff.Retry(operation, backoff.NewExponentialBackOff())
-	return app, err
-}
-
-func checkForIntermittentErrors(resp *console.Response, err error) error {
-	if resp == nil || resp.StatusCode > 500 {
-		return err
-	}
-	if resp.StatusCode == http.StatusInternalServerError {
-		return backoff.Permanent(fmt.Errorf("console: %s %w", resp.Error.Message, err))
-	}
-	if resp.StatusCode == http.StatusBadRequest &&
-		strings.Contains(resp.Error.Message, "invalid character") {
-		return ErrIntermittent
-	}
-	return backoff.Permanent(err)
-}
@@ -0,0 +1,135 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testSeedAutoscaler binds test-app to the fake metrics instance with autoscaling off
+func testSeedAutoscaler(f *fakeHSDP) {
+	f.put("Autoscaler", fakeObject{
+		"id":           "fake-metrics-instance/test-app",
+		"name":         "test-app",
+		"enabled":      false,
+		"minInstances": 1,
+		"maxInstances": 1,
+		"thresholds": []fakeObject{
+			{"name": "cpu", "enabled": false, "min": 5, "max": 100},
+			{"name": "memory", "enabled": false, "min": 20, "max": 100},
+			{"name": "http-rate", "enabled": false, "min": 300, "max": 6000000},
+			{"name": "http-latency", "enabled": false, "min": 10, "max": 10000},
+		},
+	})
+}
+
+func testMetricsAutoscalerConfig(f *fakeHSDP, maxInstances int) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_metrics_autoscaler" "test" {
+  metrics_instance_id = "fake-metrics-instance"
+  app_name            = "test-app"
+  enabled             = true
+  min_instances       = 2
+  max_instances       = %d
+
+  threshold_cpu {
+    enabled = true
+    min     = 10
+    max     = 90
+  }
+  threshold_memory {
+    enabled = false
+  }
+  threshold_http_latency {
+    enabled = false
+  }
+  threshold_http_rate {
+    enabled = false
+  }
+}
+`, maxInstances)
+}
+
+// testCheckAutoscaler checks the settings of test-app in the fake
+func testCheckAutoscaler(f *fakeHSDP, enabled bool, maxInstances int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		app := f.get("Autoscaler", "fake-metrics-instance/test-app")
+		if app == nil {
+			return fmt.Errorf("test-app is not bound to the metrics instance")
+		}
+		if app["enabled"] != enabled || fmt.Sprint(app["maxInstances"]) != fmt.Sprint(maxInstances) {
+			return fmt.Errorf("expected enabled=%t maxInstances=%d, got enabled=%v maxInstances=%v",
+				enabled, maxInstances, app["enabled"], app["maxInstances"])
+		}
+		return nil
+	}
+}
+
+func TestResourceMetricsAutoscaler(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedAutoscaler(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy: func(s *terraform.State) error {
+			// Destroy only switches autoscaling off, the app stays bound
+			if app := f.get("Autoscaler", "fake-metrics-instance/test-app"); app == nil || app["enabled"] != false {
+				return fmt.Errorf("autoscaling of test-app was not disabled: %v", app)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testMetricsAutoscalerConfig(f, 10),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckAutoscaler(f, true, 10),
+					resource.TestCheckResourceAttr("hsdp_metrics_autoscaler.test", "id", "fake-metrics-instancetest-app"),
+					resource.TestCheckResourceAttr("hsdp_metrics_autoscaler.test", "min_instances", "2"),
+				),
+			},
+			{
+				Config: testMetricsAutoscalerConfig(f, 5),
+				Check:  testCheckAutoscaler(f, true, 5),
+			},
+			{
+				ResourceName:      "hsdp_metrics_autoscaler.test",
+				ImportState:       true,
+				ImportStateId:     "fake-metrics-instance,test-app",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceMetricsAutoscalerDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedAutoscaler(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testMetricsAutoscalerConfig(f, 10),
+			},
+			{
+				// Autoscaling was switched off in the Console
+				PreConfig: func() {
+					f.update("Autoscaler", "fake-metrics-instance/test-app", fakeObject{"enabled": false})
+				},
+				Config: testMetricsAutoscalerConfig(f, 10),
+				Check:  testCheckAutoscaler(f, true, 10),
+			},
+			{
+				// The app was deleted, there is nothing left to configure
+				PreConfig: func() {
+					f.remove("Autoscaler", "fake-metrics-instance/test-app")
+				},
+				Config:             testMetricsAutoscalerConfig(f, 10),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -12,13 +12,15 @@ import (
 func resourcePKICert() *schema.Resource {
 	return &schema.Resource{
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithFields("tenant_id"),
 		},
 		CreateContext: resourcePKICertCreate,
 		ReadContext:   resourcePKICertRead,
+		UpdateContext: updateCredentialsOnly,
 		DeleteContext: resourcePKICertDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"tenant_id": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -102,7 +104,7 @@ func resourcePKICertCreate(_ context.Context, d *schema.ResourceData, m interfac
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -113,9 +115,9 @@ func resourcePKICertCreate(_ context.Context, d *schema.ResourceData, m interfac
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("create PKI cert logicalPath: %w", err))
 	}
-	tenant, _, err := client.Tenants.Retrieve(logicalPath)
+	tenant, resp, err := client.Tenants.Retrieve(logicalPath)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	roleName := d.Get("role").(string)
 	ttl := d.Get("ttl").(string)
@@ -140,9 +142,9 @@ func resourcePKICertCreate(_ context.Context, d *schema.ResourceData, m interfac
 		PrivateKeyFormat:  "pem",
 		Format:            "pem",
 	}
-	cert, _, err := client.Services.IssueCertificate(logicalPath, role.Name, certRequest)
+	cert, resp, err := client.Services.IssueCertificate(logicalPath, role.Name, certRequest)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("issue PKI cert: %w", err))
+		return diagFromResponse(fmt.Errorf("issue PKI cert: %w", err), resp)
 	}
 	d.SetId(cert.Data.SerialNumber)
 	err = certToSchema(cert, d, m)
@@ -171,7 +173,7 @@ func resourcePKICertRead(_ context.Context, d *schema.ResourceData, m interface{
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -182,9 +184,13 @@ func resourcePKICertRead(_ context.Context, d *schema.ResourceData, m interface{
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("read PKI cert logicalPath: %w", err))
 	}
-	cert, _, err := client.Services.GetCertificateBySerial(logicalPath, d.Id())
+	cert, resp, err := client.Services.GetCertificateBySerial(logicalPath, d.Id())
+	if pkiNotFound(err) {
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("read PKI cert: %w", err))
+		return diagFromResponse(fmt.Errorf("read PKI cert: %w", err), resp)
 	}
 	err = certToSchema(cert, d, m)
 	if err != nil {
@@ -199,7 +205,7 @@ func resourcePKICertDelete(_ context.Context, d *schema.ResourceData, m interfac
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -210,9 +216,9 @@ func resourcePKICertDelete(_ context.Context, d *schema.ResourceData, m interfac
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("delete PKI cert logicalPath: %w", err))
 	}
-	revoke, _, err := client.Services.RevokeCertificateBySerial(logicalPath, d.Id())
+	revoke, resp, err := client.Services.RevokeCertificateBySerial(logicalPath, d.Id())
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("delete PKI cert: %w", err))
+		return diagFromResponse(fmt.Errorf("delete PKI cert: %w", err), resp)
 	}
 	if revoke.Data.RevocationTime > 0 {
 		d.SetId("")
@@ -2,10 +2,12 @@ package hsdp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/philips-software/go-hsdp-api/pki"
+	"net/http"
 )
 
 func resourcePKITenant() *schema.Resource {
@@ -19,6 +21,7 @@ func resourcePKITenant() *schema.Resource {
 		DeleteContext: resourcePKITenantDelete,
 
 		Schema: map[string]*schema.Schema{
+			"credentials": credentialsSchema(),
 			"organization_name": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -142,7 +145,7 @@ func resourcePKITenantDelete(_ context.Context, d *schema.ResourceData, m interf
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -172,7 +175,7 @@ func resourcePKITenantUpdate(_ context.Context, d *schema.ResourceData, m interf
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -188,9 +191,9 @@ func resourcePKITenantUpdate(_ context.Context, d *schema.ResourceData, m interf
 	}
 	//logicalPath is already determined
 	tenant.ServiceParameters.LogicalPath = logicalPath
-	_, _, err = client.Tenants.Update(*tenant)
+	_, resp, err := client.Tenants.Update(*tenant)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	return diags
 }
@@ -296,7 +299,7 @@ func resourcePKITenantCreate(ctx context.Context, d *schema.ResourceData, m inte
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -306,12 +309,12 @@ func resourcePKITenantCreate(ctx context.Context, d *schema.ResourceData, m inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	resp, _, err := client.Tenants.Onboard(*tenant)
+	onboarded, resp, err := client.Tenants.Onboard(*tenant)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
-	_ = d.Set("api_endpoint", resp.APIEndpoint)
-	d.SetId(string(resp.APIEndpoint))
+	_ = d.Set("api_endpoint", onboarded.APIEndpoint)
+	d.SetId(string(onboarded.APIEndpoint))
 	return resourcePKITenantRead(ctx, d, m)
 }
 
@@ -321,7 +324,7 @@ func resourcePKITenantRead(_ context.Context, d *schema.ResourceData, m interfac
 	var err error
 	var client *pki.Client
 
-	client, err = config.PKIClient()
+	client, err = config.pkiClientFor(principalFromResourceData(d))
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("read PKI Tenant client: %w", err))
 	}
@@ -331,6 +334,10 @@ func resourcePKITenantRead(_ context.Context, d *schema.ResourceData, m interfac
 		return diag.FromErr(fmt.Errorf("read PKI Tenant logical path: %w", err))
 	}
 	tenant, _, err := client.Tenants.Retrieve(logicalPath)
+	if pkiNotFound(err) {
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("read PKI tenant retrieve: %w", err))
 	}
@@ -339,3 +346,11 @@ func resourcePKITenantRead(_ context.Context, d *schema.ResourceData, m interfac
 	}
 	return diags
 }
+
+// pkiNotFound reports whether err is a 404 of the PKI service. The PKI client
+// drops the response on errors, only the error carries the status code
+func pkiNotFound(err error) bool {
+	var errorResponse *pki.ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil &&
+		errorResponse.Response.StatusCode == http.StatusNotFound
+}
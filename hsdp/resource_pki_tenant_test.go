@@ -0,0 +1,89 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testPKITenantConfig(f *fakeHSDP, iamOrgs string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_pki_tenant" "test" {
+  organization_name = "client-test-org"
+  space_name        = "prod"
+  iam_orgs          = %s
+
+  ca {
+    common_name = "Test CA"
+  }
+
+  role {
+    name               = "ec256"
+    allow_any_name     = true
+    allow_ip_sans      = true
+    allow_subdomains   = true
+    allowed_other_sans = ["*"]
+    allowed_uri_sans   = ["*"]
+    client_flag        = true
+    server_flag        = true
+    enforce_hostnames  = true
+    key_bits           = 256
+    key_type           = "ec"
+  }
+}
+`, iamOrgs)
+}
+
+func TestResourcePKITenant(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "PKITenant", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testPKITenantConfig(f, `["fake-root-org"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_pki_tenant.test", "logical_path", "client-test-org-prod"),
+					resource.TestCheckResourceAttr("hsdp_pki_tenant.test", "id", f.URL+"/core/pki/api/client-test-org-prod"),
+					resource.TestCheckResourceAttr("hsdp_pki_tenant.test", "service_name", "hsdp-pki"),
+					resource.TestCheckResourceAttr("hsdp_pki_tenant.test", "role.#", "1"),
+				),
+			},
+			{
+				Config: testPKITenantConfig(f, `["fake-root-org", "fake-child-org"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_pki_tenant.test", "iam_orgs.#", "2"),
+					testCheckFakeCount(f, "PKITenant", 1),
+				),
+			},
+			{
+				ResourceName:      "hsdp_pki_tenant.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourcePKITenantDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testPKITenantConfig(f, `["fake-root-org"]`),
+			},
+			{
+				// The tenant was offboarded outside Terraform
+				PreConfig: func() {
+					f.remove("PKITenant", "client-test-org-prod")
+				},
+				Config: testPKITenantConfig(f, `["fake-root-org"]`),
+				Check:  testCheckFakeCount(f, "PKITenant", 1),
+			},
+		},
+	})
+}
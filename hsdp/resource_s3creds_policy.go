@@ -12,6 +12,9 @@ import (
 
 func resourceS3CredsPolicy() *schema.Resource {
 	return &schema.Resource{
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithFields("product_key"),
+		},
 		CreateContext: resourceS3CredsPolicyCreate,
 		ReadContext:   resourceS3CredsPolicyRead,
 		DeleteContext: resourceS3CredsPolicyDelete,
@@ -54,9 +57,9 @@ func resourceS3CredsPolicyCreate(_ context.Context, d *schema.ResourceData, m in
 	}
 	policy.ProductKey = productKey
 
-	createdPolicy, _, err := client.Policy.CreatePolicy(policy)
+	createdPolicy, resp, err := client.Policy.CreatePolicy(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	d.SetId(strconv.Itoa(createdPolicy.ID))
 	return diags
@@ -78,12 +81,12 @@ func resourceS3CredsPolicyRead(_ context.Context, d *schema.ResourceData, m inte
 	}
 	productKey := d.Get("product_key").(string)
 
-	policies, _, err := client.Policy.GetPolicy(&creds.GetPolicyOptions{
+	policies, resp, err := client.Policy.GetPolicy(&creds.GetPolicyOptions{
 		ID:         &id,
 		ProductKey: &productKey,
 	})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if len(policies) != 1 { // Policy was deleted
 		d.SetId("")
@@ -99,7 +102,7 @@ func resourceS3CredsPolicyRead(_ context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 
 	}
-	_ = d.Set("policy", policyJSON)
+	_ = d.Set("policy", string(policyJSON))
 	_ = d.Set("product_key", productKey)
 	return diags
 }
@@ -123,9 +126,9 @@ func resourceS3CredsPolicyDelete(_ context.Context, d *schema.ResourceData, m in
 		ID:         id,
 		ProductKey: productKey,
 	}
-	ok, _, err := client.Policy.DeletePolicy(policy)
+	ok, resp, err := client.Policy.DeletePolicy(policy)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromResponse(err, resp)
 	}
 	if ok {
 		d.SetId("")
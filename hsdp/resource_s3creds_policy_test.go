@@ -0,0 +1,129 @@
+package hsdp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testS3CredsPolicyConfig(f *fakeHSDP, productKey, actions string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_s3creds_policy" "test" {
+  product_key = %q
+  policy      = <<POLICY
+{
+  "conditions": {
+    "managingOrganizations": [ "fake-root-org" ],
+    "groups": [ "PublishGroup" ]
+  },
+  "allowed": {
+    "resources": [ "fake-root-org/*" ],
+    "actions": %s
+  }
+}
+POLICY
+}
+`, productKey, actions)
+}
+
+func TestResourceS3CredsPolicy(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "S3CredsPolicy/fake-product-key", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET", "PUT" ]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "S3CredsPolicy/fake-product-key", "hsdp_s3creds_policy.test"),
+					testCheckFakeCount(f, "S3CredsPolicy/other-product-key", 0),
+				),
+			},
+			{
+				// A reordered policy is the same policy
+				Config:   testS3CredsPolicyConfig(f, "fake-product-key", `[ "PUT", "GET" ]`),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "hsdp_s3creds_policy.test",
+				ImportState:       true,
+				ImportStateIdFunc: testImportID("hsdp_s3creds_policy.test", "fake-product-key"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceS3CredsPolicyReplace(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET" ]`),
+				Check:  testSaveID("hsdp_s3creds_policy.test", &id),
+			},
+			{
+				// Policies can not be updated, a change replaces the policy
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET", "LIST" ]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeRecreated(f, "S3CredsPolicy/fake-product-key", "hsdp_s3creds_policy.test", &id),
+					testCheckFakeCount(f, "S3CredsPolicy/fake-product-key", 1),
+				),
+			},
+			{
+				// The policy moves to the bucket of the new product key
+				Config: testS3CredsPolicyConfig(f, "other-product-key", `[ "GET", "LIST" ]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "S3CredsPolicy/other-product-key", "hsdp_s3creds_policy.test"),
+					testCheckFakeCount(f, "S3CredsPolicy/fake-product-key", 0),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceS3CredsPolicyDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET" ]`),
+				Check:  testSaveID("hsdp_s3creds_policy.test", &id),
+			},
+			{
+				// A deleted policy is answered with an empty list
+				PreConfig: func() {
+					f.remove("S3CredsPolicy/fake-product-key", id)
+				},
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET" ]`),
+				Check:  testCheckFakeRecreated(f, "S3CredsPolicy/fake-product-key", "hsdp_s3creds_policy.test", &id),
+			},
+		},
+	})
+}
+
+func TestResourceS3CredsPolicyRetry(t *testing.T) {
+	f := newFakeHSDP(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					f.failNext(http.MethodGet, "/core/credentials/Policy", http.StatusServiceUnavailable, 2)
+				},
+				Config: testS3CredsPolicyConfig(f, "fake-product-key", `[ "GET" ]`),
+				Check:  testCheckFakeExists(f, "S3CredsPolicy/fake-product-key", "hsdp_s3creds_policy.test"),
+			},
+		},
+	})
+}
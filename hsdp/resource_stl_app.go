@@ -129,7 +129,7 @@ func resourceSTLAppRead(ctx context.Context, d *schema.ResourceData, m interface
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("stl_app: decode content: %w", err))
 	}
-	_ = d.Set("content", content)
+	_ = d.Set("content", string(content))
 	_ = d.Set("device_id", resource.DeviceID)
 	device, err := client.Devices.GetDeviceByID(ctx, resource.DeviceID)
 	if err == nil {
@@ -140,7 +140,6 @@ func resourceSTLAppRead(ctx context.Context, d *schema.ResourceData, m interface
 
 func resourceSTLAppCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	config := m.(*Config)
-	var diags diag.Diagnostics
 	var client *stl.Client
 	var err error
 
@@ -166,5 +165,5 @@ func resourceSTLAppCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	}
 	d.SetId(fmt.Sprintf("%d", resource.ID))
 	syncSTLIfNeeded(ctx, client, d, m)
-	return diags
+	return resourceSTLAppRead(ctx, d, m)
 }
@@ -0,0 +1,112 @@
+package hsdp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testSTLAppConfig(f *fakeHSDP, content string, sync bool) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_stl_app" "test" {
+  serial_number = "SN-0001"
+  name          = "terraform.yml"
+  content       = %q
+  sync          = %t
+}
+`, content, sync)
+}
+
+// testCheckSTLApp checks the decoded content of the app in the fake
+func testCheckSTLApp(f *fakeHSDP, content string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["hsdp_stl_app.test"]
+		if !ok {
+			return fmt.Errorf("hsdp_stl_app.test not found in state")
+		}
+		app := f.get("STLApp", rs.Primary.ID)
+		if app == nil {
+			return fmt.Errorf("app %s not found", rs.Primary.ID)
+		}
+		got, err := base64.StdEncoding.DecodeString(fmt.Sprint(f.fakeCopy(app)["content"]))
+		if err != nil {
+			return fmt.Errorf("content is not base64 encoded: %w", err)
+		}
+		if string(got) != content {
+			return fmt.Errorf("expected content %q, got %q", content, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceSTLApp(t *testing.T) {
+	f := newFakeHSDP(t)
+	deviceID := testSeedSTLDevice(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "STLApp", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLAppConfig(f, "kind: Secret\n", true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSTLApp(f, "kind: Secret\n"),
+					testSaveID("hsdp_stl_app.test", &id),
+					resource.TestCheckResourceAttr("hsdp_stl_app.test", "device_id", deviceID),
+					testCheckSTLSyncs(f, deviceID, 1),
+				),
+			},
+			{
+				Config: testSTLAppConfig(f, "kind: ConfigMap\n", true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSTLApp(f, "kind: ConfigMap\n"),
+					resource.TestCheckResourceAttrPtr("hsdp_stl_app.test", "id", &id),
+					testCheckSTLSyncs(f, deviceID, 2),
+				),
+			},
+			{
+				// Without sync the config is only pushed to STL
+				Config: testSTLAppConfig(f, "kind: Pod\n", false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSTLApp(f, "kind: Pod\n"),
+					testCheckSTLSyncs(f, deviceID, 2),
+				),
+			},
+			{
+				ResourceName:      "hsdp_stl_app.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// sync is not kept by STL
+				ImportStateVerifyIgnore: []string{"sync"},
+			},
+		},
+	})
+}
+
+func TestResourceSTLAppDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedSTLDevice(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLAppConfig(f, "kind: Secret\n", true),
+				Check:  testSaveID("hsdp_stl_app.test", &id),
+			},
+			{
+				// The content was changed on the device
+				PreConfig: func() {
+					f.update("STLApp", id, fakeObject{"content": base64.StdEncoding.EncodeToString([]byte("kind: Pod\n"))})
+				},
+				Config: testSTLAppConfig(f, "kind: Secret\n", true),
+				Check:  testCheckSTLApp(f, "kind: Secret\n"),
+			},
+		},
+	})
+}
@@ -256,6 +256,7 @@ func resourceSTLConfigRead(ctx context.Context, d *schema.ResourceData, m interf
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	_ = d.Set("serial_number", serialNumber)
 	return diags
 }
 
@@ -0,0 +1,105 @@
+package hsdp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testSTLConfigConfig(f *fakeHSDP, tcp string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_stl_config" "test" {
+  serial_number = "SN-0001"
+
+  firewall_exceptions {
+    tcp = %s
+    udp = [53]
+  }
+
+  logging {
+    raw_config = "[OUTPUT]\n    Name stdout\n"
+  }
+}
+`, tcp)
+}
+
+// testCheckSTLFirewall checks the TCP firewall exceptions of the device,
+// in any order
+func testCheckSTLFirewall(f *fakeHSDP, deviceID string, tcp ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		config, _ := f.fakeCopy(f.get("STLDevice", deviceID))["appFirewallException"].(fakeObject)
+		var got []string
+		for _, port := range toInterfaceList(config["tcp"]) {
+			got = append(got, fmt.Sprint(port))
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tcp, ",") {
+			return fmt.Errorf("expected TCP exceptions %v, got %v", tcp, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceSTLConfig(t *testing.T) {
+	f := newFakeHSDP(t)
+	deviceID := testSeedSTLDevice(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		// Destroy clears the config, the device stays
+		CheckDestroy: testCheckSTLFirewall(f, deviceID),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLConfigConfig(f, "[443]"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSTLFirewall(f, deviceID, "443"),
+					resource.TestCheckResourceAttr("hsdp_stl_config.test", "id", "SN-0001"),
+					resource.TestCheckResourceAttr("hsdp_stl_config.test", "logging.0.raw_config", "[OUTPUT]\n    Name stdout\n"),
+					testCheckSTLSyncs(f, deviceID, 1),
+				),
+			},
+			{
+				Config: testSTLConfigConfig(f, "[443, 8443]"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSTLFirewall(f, deviceID, "443", "8443"),
+					testCheckSTLSyncs(f, deviceID, 2),
+				),
+			},
+			{
+				ResourceName:            "hsdp_stl_config.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"sync"},
+			},
+		},
+	})
+}
+
+func TestResourceSTLConfigDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	deviceID := testSeedSTLDevice(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLConfigConfig(f, "[443]"),
+			},
+			{
+				// A port was opened on the device
+				PreConfig: func() {
+					device := f.fakeCopy(f.get("STLDevice", deviceID))
+					f.update("STLDevice", deviceID, fakeObject{
+						"appFirewallException": fakeObject{"deviceId": device["id"], "tcp": []int{22, 443}, "udp": []int{53}},
+					})
+				},
+				Config: testSTLConfigConfig(f, "[443]"),
+				Check:  testCheckSTLFirewall(f, deviceID, "443"),
+			},
+		},
+	})
+}
@@ -122,6 +122,10 @@ func resourceSTLCustomCertRead(ctx context.Context, d *schema.ResourceData, m in
 	_ = d.Set("name", readCert.Name)
 	_ = d.Set("cert_pem", readCert.Cert)
 	_ = d.Set("private_key_pem", readCert.Key)
+	device, err := client.Devices.GetDeviceByID(ctx, readCert.DeviceID)
+	if err == nil {
+		_ = d.Set("serial_number", device.SerialNumber)
+	}
 	return diags
 }
 
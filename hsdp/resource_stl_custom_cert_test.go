@@ -0,0 +1,84 @@
+package hsdp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testSTLCustomCertConfig(f *fakeHSDP, name, cert string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_stl_custom_cert" "test" {
+  serial_number   = "SN-0001"
+  name            = %q
+  private_key_pem = "fake-key"
+  cert_pem        = %q
+}
+`, name, cert)
+}
+
+func TestResourceSTLCustomCert(t *testing.T) {
+	f := newFakeHSDP(t)
+	deviceID := testSeedSTLDevice(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		CheckDestroy:      testCheckFakeCount(f, "STLCert", 0),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLCustomCertConfig(f, "edge-cert", "fake-cert"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFakeExists(f, "STLCert", "hsdp_stl_custom_cert.test"),
+					testSaveID("hsdp_stl_custom_cert.test", &id),
+					testCheckSTLSyncs(f, deviceID, 1),
+				),
+			},
+			{
+				Config: testSTLCustomCertConfig(f, "edge-cert-renewed", "fake-cert-2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("hsdp_stl_custom_cert.test", "id", &id),
+					resource.TestCheckResourceAttr("hsdp_stl_custom_cert.test", "name", "edge-cert-renewed"),
+					testCheckSTLSyncs(f, deviceID, 2),
+				),
+			},
+			{
+				ResourceName:            "hsdp_stl_custom_cert.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"sync"},
+			},
+		},
+	})
+}
+
+func TestResourceSTLCustomCertDrift(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedSTLDevice(f)
+	var id string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLCustomCertConfig(f, "edge-cert", "fake-cert"),
+				Check:  testSaveID("hsdp_stl_custom_cert.test", &id),
+			},
+			{
+				// The certificate was replaced on the device
+				PreConfig: func() {
+					f.update("STLCert", id, fakeObject{"cert": "other-cert"})
+				},
+				Config: testSTLCustomCertConfig(f, "edge-cert", "fake-cert"),
+				Check: func(s *terraform.State) error {
+					if cert := f.fakeCopy(f.get("STLCert", id))["cert"]; cert != "fake-cert" {
+						return fmt.Errorf("expected the certificate to be restored, got %v", cert)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -10,7 +10,10 @@ import (
 
 func resourceSTLSync() *schema.Resource {
 	return &schema.Resource{
-		Description:   `The ` + "`hsdp_stl_sync`" + ` resource syncs device config to the actual device.`,
+		Description: `The ` + "`hsdp_stl_sync`" + ` resource syncs device config to the actual device.`,
+		Importer: &schema.ResourceImporter{
+			StateContext: importSTLSyncContext,
+		},
 		CreateContext: resourceSTLSyncCreate,
 		ReadContext:   resourceSTLSyncRead,
 		DeleteContext: resourceSTLSyncDelete,
@@ -31,6 +34,12 @@ func resourceSTLSync() *schema.Resource {
 	}
 }
 
+// importSTLSyncContext imports a sync by serial number. The triggers are not known
+func importSTLSyncContext(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	_ = d.Set("serial_number", d.Id())
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceSTLSyncDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
 	d.SetId("")
 	return diag.Diagnostics{}
@@ -0,0 +1,70 @@
+package hsdp
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func testSTLSyncConfig(f *fakeHSDP, serialNumber, trigger string) string {
+	return f.providerConfig() + fmt.Sprintf(`
+resource "hsdp_stl_sync" "test" {
+  serial_number = %q
+
+  triggers = {
+    config = %q
+  }
+}
+`, serialNumber, trigger)
+}
+
+func TestResourceSTLSync(t *testing.T) {
+	f := newFakeHSDP(t)
+	deviceID := testSeedSTLDevice(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testSTLSyncConfig(f, "SN-0001", "v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("hsdp_stl_sync.test", "id", "SN-0001"),
+					testCheckSTLSyncs(f, deviceID, 1),
+				),
+			},
+			{
+				// Nothing changed, nothing to sync
+				Config: testSTLSyncConfig(f, "SN-0001", "v1"),
+				Check:  testCheckSTLSyncs(f, deviceID, 1),
+			},
+			{
+				Config: testSTLSyncConfig(f, "SN-0001", "v2"),
+				Check:  testCheckSTLSyncs(f, deviceID, 2),
+			},
+			{
+				// Importing does not sync, the triggers are not known
+				ResourceName:            "hsdp_stl_sync.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"triggers"},
+			},
+		},
+	})
+}
+
+func TestResourceSTLSyncUnknownDevice(t *testing.T) {
+	f := newFakeHSDP(t)
+	testSeedSTLDevice(f)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: f.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testSTLSyncConfig(f, "SN-9999", "v1"),
+				ExpectError: regexp.MustCompile(`hsdp_stl_sync: 404: device not found`),
+			},
+		},
+	})
+}
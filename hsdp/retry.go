@@ -0,0 +1,265 @@
+package hsdp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryPolicy decides which failed API requests are retried and how long to
+// wait in between. It is shared by every service client the provider sets up
+type retryPolicy struct {
+	statusCodes map[int]bool
+	service     func(u *url.URL) string
+}
+
+func newRetryPolicy(statusCodes []int) *retryPolicy {
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryStatusCodes
+	}
+	p := &retryPolicy{statusCodes: make(map[int]bool), service: serviceOfURL}
+	for _, code := range statusCodes {
+		p.statusCodes[code] = true
+	}
+	return p
+}
+
+// checkRetry retries connection errors and the configured status codes. Requests
+// which are not idempotent are only retried when HSDP explicitly throttled them,
+// as a timeout does not tell us whether e.g. a Cartel create went through
+func (p *retryPolicy) checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	idempotent := idempotentMethod(requestMethod(ctx, resp))
+	if err != nil || resp == nil {
+		if !idempotent {
+			return false, nil
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return p.statusCodes[resp.StatusCode], nil
+	}
+	if !idempotent {
+		return false, nil
+	}
+	if p.intermittentConsoleError(resp) {
+		return true, nil
+	}
+	return p.statusCodes[resp.StatusCode], nil
+}
+
+const maxIntermittentBody = 64 * 1024
+
+// intermittentConsoleError detects the 400 responses Console intermittently
+// returns when it fails to parse its own backend response, most notably on
+// metrics autoscaler updates, which are a PUT. The body is restored for the caller
+func (p *retryPolicy) intermittentConsoleError(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusBadRequest || resp.Request == nil || resp.Body == nil ||
+		p.service(resp.Request.URL) != "console" {
+		return false
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxIntermittentBody))
+	rest := resp.Body
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rest), rest}
+	return err == nil && bytes.Contains(body, []byte("invalid character"))
+}
+
+type requestMethodKey struct{}
+
+// requestMethod returns the method of the request resp or err belongs to. On
+// transport errors there is no response, so methodTransport stores it in ctx
+func requestMethod(ctx context.Context, resp *http.Response) string {
+	if resp != nil && resp.Request != nil {
+		return resp.Request.Method
+	}
+	method, _ := ctx.Value(requestMethodKey{}).(string)
+	return method
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPatch:
+		return false
+	}
+	return true
+}
+
+// methodTransport passes the request method to checkRetry through the context
+type methodTransport struct {
+	next http.RoundTripper
+}
+
+func (t *methodTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := context.WithValue(req.Context(), requestMethodKey{}, req.Method)
+	return t.next.RoundTrip(req.WithContext(ctx))
+}
+
+// backoff honours the Retry-After header when present, capped at max, and falls
+// back to exponential backoff between min and max otherwise
+func (p *retryPolicy) backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+}
+
+// retryAfter parses a Retry-After header value in either seconds or HTTP-date form
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// newHTTPClient wraps transport in the debug log, rate limits, error capturing,
+// IAM token renewal and the provider retry policy
+func (c *Config) newHTTPClient(transport http.RoundTripper) *http.Client {
+	policy := newRetryPolicy(c.RetryStatusCodes)
+	policy.service = c.serviceFor
+
+	if c.debugLogger != nil {
+		transport = &loggingTransport{next: transport, logger: c.debugLogger}
+	}
+	if limiters := c.rateLimiters(); len(limiters) > 0 {
+		transport = &rateLimitTransport{
+			next:     transport,
+			limiters: limiters,
+			service:  c.serviceFor,
+			logger:   c.debugLogger,
+		}
+	}
+	transport = &errorCaptureTransport{next: transport}
+	transport = &authTransport{next: transport, sessions: &c.iamSessions}
+
+	retryClient := retryablehttp.NewClient()
+	retryClient.HTTPClient = &http.Client{Transport: transport}
+	retryClient.Logger = retryLogger{logger: c.debugLogger}
+	retryClient.RetryMax = c.RetryMax
+	if c.RetryWaitMin > 0 {
+		retryClient.RetryWaitMin = c.RetryWaitMin
+	}
+	if c.RetryWaitMax > 0 {
+		retryClient.RetryWaitMax = c.RetryWaitMax
+	}
+	retryClient.CheckRetry = policy.checkRetry
+	retryClient.Backoff = policy.backoff
+	// Hand the final response back so clients can still inspect the status code
+	retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	client := retryClient.StandardClient()
+	client.Transport = &methodTransport{next: client.Transport}
+	return client
+}
+
+// retryLogger writes the messages of retryablehttp to the debug log. Its
+// default logger writes every request to stderr
+type retryLogger struct {
+	logger *debugLogger
+}
+
+func (l retryLogger) log(level, msg string, keysAndValues []interface{}) {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		msg += fmt.Sprintf(" %v=%v", keysAndValues[i], keysAndValues[i+1])
+	}
+	l.logger.Log(debugLogEntry{Type: "retry", Message: fmt.Sprintf("[%s] %s", level, msg)})
+}
+
+func (l retryLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("ERROR", msg, keysAndValues)
+}
+
+func (l retryLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("INFO", msg, keysAndValues)
+}
+
+func (l retryLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log("DEBUG", msg, keysAndValues)
+}
+
+func (l retryLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("WARN", msg, keysAndValues)
+}
+
+// httpClient returns the HTTP client shared by all service clients
+func (c *Config) httpClient() *http.Client {
+	c.httpClientOnce.Do(func() {
+		c.sharedHTTPClient = c.newHTTPClient(c.transport())
+	})
+	return c.sharedHTTPClient
+}
+
+// serviceHosts maps host name fragments to the HSDP service they belong to
+var serviceHosts = []struct {
+	fragment string
+	service  string
+}{
+	{"s3creds", "s3creds"},
+	{"cartel", "cartel"},
+	{"console", "console"},
+	{"uaa", "console"},
+	{"dicom", "dicom"},
+	{"cdr", "cdr"},
+	{"pki", "pki"},
+	{"stl", "stl"},
+	{"iam-", "iam"},
+	{"idm-", "iam"},
+}
+
+// serviceName returns the HSDP service serving host, or the host itself when unknown
+func serviceName(host string) string {
+	h := strings.ToLower(host)
+	for _, s := range serviceHosts {
+		if strings.Contains(h, s.fragment) {
+			return s.service
+		}
+	}
+	return host
+}
+
+// serviceOfURL returns the HSDP service serving u judging by its host name only
+func serviceOfURL(u *url.URL) string {
+	return serviceName(u.Host)
+}
+
+// serviceHTTPClient returns a copy of the shared HTTP client for a go-hsdp-api
+// client to own. Those wrap the transport of the client they are given, which
+// must not happen to the shared one while other requests use it
+func (c *Config) serviceHTTPClient() *http.Client {
+	shared := c.httpClient()
+	return &http.Client{Transport: shared.Transport, Timeout: shared.Timeout}
+}
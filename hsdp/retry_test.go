@@ -0,0 +1,210 @@
+package hsdp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/philips-software/go-hsdp-api/console"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := newRetryPolicy(nil)
+	ctx := context.Background()
+
+	get, _ := http.NewRequest(http.MethodGet, "https://iam.example.com", nil)
+	post, _ := http.NewRequest(http.MethodPost, "https://iam.example.com", nil)
+
+	retry, _ := p.checkRetry(ctx, &http.Response{StatusCode: http.StatusServiceUnavailable, Request: get}, nil)
+	assert.True(t, retry)
+	retry, _ = p.checkRetry(ctx, &http.Response{StatusCode: http.StatusServiceUnavailable, Request: post}, nil)
+	assert.False(t, retry)
+	retry, _ = p.checkRetry(ctx, &http.Response{StatusCode: http.StatusTooManyRequests, Request: post}, nil)
+	assert.True(t, retry)
+	retry, _ = p.checkRetry(ctx, &http.Response{StatusCode: http.StatusNotFound, Request: get}, nil)
+	assert.False(t, retry)
+
+	// Transport errors are only retried for idempotent requests
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	retry, _ = p.checkRetry(context.WithValue(ctx, requestMethodKey{}, http.MethodGet), nil, reset)
+	assert.True(t, retry)
+	retry, _ = p.checkRetry(context.WithValue(ctx, requestMethodKey{}, http.MethodPost), nil, reset)
+	assert.False(t, retry)
+}
+
+func TestRetryPolicyIntermittentConsoleError(t *testing.T) {
+	p := newRetryPolicy(nil)
+	ctx := context.Background()
+
+	put, _ := http.NewRequest(http.MethodPut, "https://console.us-east.philips-healthsuite.com/v3/metrics", nil)
+	body := `{"error":"invalid character '<' looking for beginning of value"}`
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Request:    put,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+	retry, _ := p.checkRetry(ctx, resp, nil)
+	assert.True(t, retry)
+	data, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, body, string(data))
+
+	resp = &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Request:    put,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"error":"invalid name"}`)),
+	}
+	retry, _ = p.checkRetry(ctx, resp, nil)
+	assert.False(t, retry)
+}
+
+func TestRetryPolicyIntermittentConsoleErrorWithRegion(t *testing.T) {
+	// STL is served from the Console host of the region
+	c := &Config{}
+	c.Region = "us-east"
+	c.Environment = "client-test"
+	p := newRetryPolicy(nil)
+	p.service = c.serviceFor
+	ctx := context.Background()
+
+	put, _ := http.NewRequest(http.MethodPut, "https://console.na1.hsdp.io/v3/metrics/app/autoscalers/app", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Request:    put,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"error":"invalid character '<' looking for beginning of value"}`)),
+	}
+	retry, _ := p.checkRetry(ctx, resp, nil)
+	assert.True(t, retry)
+
+	stl, _ := http.NewRequest(http.MethodPost, "https://console.na1.hsdp.io/api/stl/user/v1/graphql", nil)
+	assert.Equal(t, "stl", c.serviceFor(stl.URL))
+}
+
+func TestRetryConsoleAutoscalerUpdate(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		attempt := len(methods)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if attempt == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"status":"error","error":{"message":"invalid character '<' looking for beginning of value"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":"success","data":{"application":{"name":"app","enabled":true,"minInstances":1,"maxInstances":3}}}`)
+	}))
+	defer server.Close()
+
+	// UAAURL makes the test server a Console host for the retry policy
+	c := &Config{
+		RetryMax:     2,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		UAAURL:       server.URL,
+	}
+	if !assert.Nil(t, c.setupTransport()) {
+		return
+	}
+	client, err := console.NewClient(c.newHTTPClient(c.transport()), &console.Config{
+		UAAURL:         server.URL,
+		BaseConsoleURL: server.URL + "/",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	app, _, err := client.Metrics.UpdateApplicationAutoscaler("app-guid", console.Application{
+		Name:         "app",
+		Enabled:      true,
+		MinInstances: 1,
+		MaxInstances: 3,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 3, app.MaxInstances)
+	assert.Equal(t, []string{
+		"PUT /v3/metrics/app-guid/autoscalers",
+		"PUT /v3/metrics/app-guid/autoscalers",
+	}, methods)
+}
+
+func TestRetryLoggerWritesDebugLog(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "debug.log")
+	logger, err := newDebugLogger(filename, 0, 0)
+	if !assert.Nil(t, err) {
+		return
+	}
+	c := &Config{
+		RetryMax:     1,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		debugLogger:  logger,
+	}
+	resp, err := c.newHTTPClient(http.DefaultTransport).Get(server.URL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	data, err := ioutil.ReadFile(filename)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var retries []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry debugLogEntry
+		if assert.Nil(t, json.Unmarshal([]byte(line), &entry)) && entry.Type == "retry" {
+			retries = append(retries, entry.Message)
+		}
+	}
+	if assert.NotEmpty(t, retries) {
+		assert.Contains(t, strings.Join(retries, "\n"), "[DEBUG] retrying request")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := newRetryPolicy(nil)
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+	assert.Equal(t, 30*time.Second, p.backoff(time.Second, 30*time.Second, 1, resp))
+
+	resp = &http.Response{Header: http.Header{"Retry-After": {"5"}}}
+	assert.Equal(t, 5*time.Second, p.backoff(time.Second, 30*time.Second, 1, resp))
+}
+
+func TestRetryAfter(t *testing.T) {
+	wait, ok := retryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	_, ok = retryAfter("")
+	assert.False(t, ok)
+
+	wait, ok = retryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+}
@@ -0,0 +1,65 @@
+package hsdp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// setupTransport builds the base transport shared by all service clients from
+// the TLS and proxy settings of the provider
+func (c *Config) setupTransport() error {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(c.CABundle)) {
+			return ErrInvalidCABundle
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCert != "" || c.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(c.ClientCert), []byte(c.ClientKey))
+		if err != nil {
+			return fmt.Errorf("client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = c.proxyFunc()
+	c.baseTransport = transport
+	return nil
+}
+
+// transport returns a copy of the base transport
+func (c *Config) transport() *http.Transport {
+	if c.baseTransport == nil {
+		return http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return c.baseTransport.Clone()
+}
+
+// proxyFunc returns the proxy selection used for API requests and container host
+// SSH connections. The http_proxy and no_proxy settings take precedence over
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+func (c *Config) proxyFunc() func(*http.Request) (*url.URL, error) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.HTTPProxy != "" {
+		proxyConfig.HTTPProxy = c.HTTPProxy
+		proxyConfig.HTTPSProxy = c.HTTPProxy
+	}
+	if c.NoProxy != "" {
+		proxyConfig.NoProxy = c.NoProxy
+	}
+	proxy := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
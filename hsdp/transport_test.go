@@ -0,0 +1,42 @@
+package hsdp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyFunc(t *testing.T) {
+	c := &Config{
+		HTTPProxy: "http://proxy.example.com:3128",
+		NoProxy:   "internal.example.com",
+	}
+	proxy := c.proxyFunc()
+
+	req, _ := http.NewRequest(http.MethodGet, "https://iam-client-test.us-east.philips-healthsuite.com/authorize/oauth2/token", nil)
+	proxyURL, err := proxy(req)
+	if !assert.Nil(t, err) || !assert.NotNil(t, proxyURL) {
+		return
+	}
+	assert.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+
+	req, _ = http.NewRequest(http.MethodGet, "https://internal.example.com/", nil)
+	proxyURL, err = proxy(req)
+	assert.Nil(t, err)
+	assert.Nil(t, proxyURL)
+}
+
+func TestSetupTransport(t *testing.T) {
+	c := &Config{InsecureSkipVerify: true}
+	if !assert.Nil(t, c.setupTransport()) {
+		return
+	}
+	assert.True(t, c.transport().TLSClientConfig.InsecureSkipVerify)
+
+	c = &Config{CABundle: "not a certificate"}
+	assert.Equal(t, ErrInvalidCABundle, c.setupTransport())
+
+	c = &Config{ClientCert: "not a certificate", ClientKey: "not a key"}
+	assert.NotNil(t, c.setupTransport())
+}